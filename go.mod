@@ -1,8 +1,13 @@
 module prodoctorov
 
-go 1.16
+go 1.18
 
 require (
 	go.uber.org/zap v1.18.1
 	gopkg.in/yaml.v2 v2.4.0
 )
+
+require (
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+)
@@ -0,0 +1,225 @@
+package calendar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"prodoctorov/internal/service/domino"
+)
+
+// DateLayout layout of closure dates
+const DateLayout = "2006-01-02"
+
+// RangeSeparator separates the first and the last day of a closure passed as a single string
+const RangeSeparator = ".."
+
+var (
+	ErrBadClosure    = errors.New("closure must have either date or from and to dates")
+	ErrBadRange      = errors.New("closure ends before it starts")
+	ErrNotAppendable = errors.New("closure can't be appended, calendar file must be a YAML block sequence")
+)
+
+// Closure a day or days range, both ends included, when the clinic or a part of it is closed
+type Closure struct {
+	Date    string `yaml:"date,omitempty"`
+	From    string `yaml:"from,omitempty"`
+	To      string `yaml:"to,omitempty"`
+	Filial  string `yaml:"filial,omitempty"` // filial identifier, see filials.Config
+	Spec    string `yaml:"spec,omitempty"`   // Domino specialty
+	Comment string `yaml:"comment,omitempty"`
+
+	from time.Time
+	to   time.Time
+}
+
+// NewClosure creates a closure of the day or of the days range given as "from..to"
+func NewClosure(dates string, filial string, spec string, comment string) (*Closure, error) {
+	c := &Closure{Filial: filial, Spec: spec, Comment: comment}
+
+	if from, to, ok := strings.Cut(dates, RangeSeparator); ok {
+		c.From, c.To = strings.TrimSpace(from), strings.TrimSpace(to)
+	} else {
+		c.Date = strings.TrimSpace(dates)
+	}
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Closure) check() error {
+	var err error
+
+	switch {
+	case c.Date != "" && c.From == "" && c.To == "":
+		c.from, err = time.Parse(DateLayout, c.Date)
+		c.to = c.from
+	case c.Date == "" && c.From != "" && c.To != "":
+		if c.from, err = time.Parse(DateLayout, c.From); err == nil {
+			c.to, err = time.Parse(DateLayout, c.To)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrBadClosure, c)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrBadClosure, c, err)
+	}
+
+	if c.to.Before(c.from) {
+		return fmt.Errorf("%w: %s", ErrBadRange, c)
+	}
+
+	return nil
+}
+
+func (c *Closure) String() string {
+	result := c.Date
+	if result == "" {
+		result = c.From + RangeSeparator + c.To
+	}
+
+	if c.Filial != "" {
+		result += " filial " + c.Filial
+	}
+
+	if c.Spec != "" {
+		result += " spec " + c.Spec
+	}
+
+	return result
+}
+
+// Match reports whether the record starts on a closed day of its filial and specialty
+func (c *Closure) Match(record *domino.Record) bool {
+	if c.Filial != "" && c.Filial != record.Filial {
+		return false
+	}
+
+	if c.Spec != "" && c.Spec != record.Spec {
+		return false
+	}
+
+	y, m, d := record.StartTime.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	return !day.Before(c.from) && !day.After(c.to)
+}
+
+type Calendar []*Closure
+
+// Load reads and validates the calendar file, missing file means no closures
+func Load(fileName string) (Calendar, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(fileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return Calendar{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := make(Calendar, 0)
+	if err := yaml.Unmarshal(data, &calendar); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", fileName, err)
+	}
+
+	for _, closure := range calendar {
+		if err := closure.check(); err != nil {
+			return nil, fmt.Errorf("bad closure in %s: %w", fileName, err)
+		}
+	}
+
+	return calendar, nil
+}
+
+// AddClosure appends the closure to the calendar file as a new list item, so comments and layout
+// of the file are kept
+func AddClosure(config *Config, closure *Closure) error {
+	if config.File == "" {
+		return ErrNoFile
+	}
+
+	calendar, err := Load(config.File)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Clean(config.File))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if len(data) != 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+
+	item, err := yaml.Marshal(Calendar{closure})
+	if err != nil {
+		return err
+	}
+
+	data = append(data, item...)
+
+	added := make(Calendar, 0)
+	if err := yaml.Unmarshal(data, &added); err != nil || len(added) != len(calendar)+1 {
+		return fmt.Errorf("%w: %s", ErrNotAppendable, config.File)
+	}
+
+	tmpName := config.File + ".tmp"
+
+	if err := ioutil.WriteFile(tmpName, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpName, config.File)
+}
+
+// Closed returns the first closure matched by the record, nil if the record is not closed
+func (c Calendar) Closed(record *domino.Record) *Closure {
+	for _, closure := range c {
+		if closure.Match(record) {
+			return closure
+		}
+	}
+
+	return nil
+}
+
+// Apply drops records of closed days or marks them busy, returns the records left
+// and the number of records affected
+func Apply(config *Config, calendar Calendar, records domino.Records) (domino.Records, int) {
+	if len(calendar) == 0 {
+		return records, 0
+	}
+
+	closed := 0
+	result := make(domino.Records, 0, len(records))
+
+	for _, r := range records {
+		if calendar.Closed(r) == nil {
+			result = append(result, r)
+
+			continue
+		}
+
+		closed++
+
+		if config.Action == ActionBusy {
+			r.Free = false
+			result = append(result, r)
+		}
+	}
+
+	return result, closed
+}
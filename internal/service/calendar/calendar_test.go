@@ -0,0 +1,224 @@
+package calendar_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/calendar"
+	"prodoctorov/internal/service/domino"
+)
+
+func TestApply(t *testing.T) {
+	holiday, err := calendar.NewClosure("2021-11-04", "", "", "День народного единства")
+	if err != nil {
+		t.Fatalf("NewClosure() error = %v", err)
+	}
+
+	newYear, err := calendar.NewClosure("2021-12-31..2022-01-09", "north", "Уролог", "")
+	if err != nil {
+		t.Fatalf("NewClosure() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		action     string
+		record     *domino.Record
+		wantFree   []bool
+		wantClosed int
+	}{
+		{
+			name:   "open day",
+			action: calendar.ActionDrop,
+			record: &domino.Record{
+				Spec: "Терапевт", Name: "Иванов И.И.",
+				StartTime: time.Date(2021, 11, 3, 10, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree: []bool{true},
+		},
+		{
+			name:   "holiday drop",
+			action: calendar.ActionDrop,
+			record: &domino.Record{
+				Spec: "Терапевт", Name: "Иванов И.И.",
+				StartTime: time.Date(2021, 11, 4, 10, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree:   []bool{},
+			wantClosed: 1,
+		},
+		{
+			name:   "holiday busy",
+			action: calendar.ActionBusy,
+			record: &domino.Record{
+				Spec: "Терапевт", Name: "Иванов И.И.",
+				StartTime: time.Date(2021, 11, 4, 10, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree:   []bool{false},
+			wantClosed: 1,
+		},
+		{
+			name:   "range of filial and spec",
+			action: calendar.ActionDrop,
+			record: &domino.Record{
+				Spec: "Уролог", Name: "Козлов К.К.", Filial: "north",
+				StartTime: time.Date(2022, 1, 3, 10, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree:   []bool{},
+			wantClosed: 1,
+		},
+		{
+			name:   "range of other filial",
+			action: calendar.ActionDrop,
+			record: &domino.Record{
+				Spec: "Уролог", Name: "Козлов К.К.", Filial: "south",
+				StartTime: time.Date(2022, 1, 3, 10, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree: []bool{true},
+		},
+		{
+			name:   "range of other spec",
+			action: calendar.ActionDrop,
+			record: &domino.Record{
+				Spec: "Терапевт", Name: "Иванов И.И.", Filial: "north",
+				StartTime: time.Date(2021, 12, 31, 10, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree: []bool{true},
+		},
+		{
+			name:   "last day of range",
+			action: calendar.ActionBusy,
+			record: &domino.Record{
+				Spec: "Уролог", Name: "Козлов К.К.", Filial: "north",
+				StartTime: time.Date(2022, 1, 9, 23, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree:   []bool{false},
+			wantClosed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			config := calendar.Config{File: filepath.Join(t.TempDir(), "calendar.yaml"), Action: tt.action}
+			if err := config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			got, closed := calendar.Apply(&config, calendar.Calendar{holiday, newYear}, domino.Records{tt.record})
+
+			gotFree := make([]bool, 0, len(got))
+			for _, r := range got {
+				gotFree = append(gotFree, r.Free)
+			}
+
+			if !reflect.DeepEqual(gotFree, tt.wantFree) || closed != tt.wantClosed {
+				t.Errorf("Apply() got = %v, %d, want %v, %d", gotFree, closed, tt.wantFree, tt.wantClosed)
+			}
+		})
+	}
+}
+
+func TestAddClosure(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr error
+	}{
+		{name: "missing file", want: 2},
+		{
+			name: "comments kept",
+			data: "# closed days\n- date: 2021-11-04\n  comment: День народного единства # holiday", // w/o trailing newline
+			want: 3,
+		},
+		{name: "flow sequence", data: "[{date: 2021-11-04}]\n", wantErr: calendar.ErrNotAppendable},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			config := calendar.Config{File: filepath.Join(t.TempDir(), "calendar.yaml")}
+
+			if tt.data != "" {
+				if err := ioutil.WriteFile(config.File, []byte(tt.data), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			for _, dates := range []string{"2021-12-31..2022-01-09", "2022-03-08"} {
+				closure, err := calendar.NewClosure(dates, "", "Уролог", "")
+				if err != nil {
+					t.Fatalf("NewClosure() error = %v", err)
+				}
+
+				if err := calendar.AddClosure(&config, closure); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("AddClosure() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+
+			data, err := ioutil.ReadFile(config.File)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.HasPrefix(string(data), tt.data) {
+				t.Errorf("AddClosure() changed the file:\n%s", data)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			closures, err := config.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			last := closures[len(closures)-1]
+			if len(closures) != tt.want || last.Date != "2022-03-08" || last.Spec != "Уролог" {
+				t.Errorf("Load() got = %v", closures)
+			}
+		})
+	}
+}
+
+func TestNewClosure(t *testing.T) {
+	tests := []struct {
+		name     string
+		dates    string
+		wantDate string
+		wantFrom string
+		wantTo   string
+		wantErr  error
+	}{
+		{name: "day", dates: "2021-11-04", wantDate: "2021-11-04"},
+		{name: "range", dates: "2021-12-31..2022-01-09", wantFrom: "2021-12-31", wantTo: "2022-01-09"},
+		{name: "bad range", dates: "2022-01-09..2021-12-31", wantErr: calendar.ErrBadRange},
+		{name: "bad date", dates: "04.11.2021", wantErr: calendar.ErrBadClosure},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			closure, err := calendar.NewClosure(tt.dates, "", "", "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewClosure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && (closure.Date != tt.wantDate || closure.From != tt.wantFrom || closure.To != tt.wantTo) {
+				t.Errorf("NewClosure() got = %v", closure)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+)
+
+// actions on time cells of closed days
+const (
+	ActionDrop = "drop" // cells are not published
+	ActionBusy = "busy" // cells are published as busy
+
+	DefaultAction = ActionDrop
+)
+
+var (
+	ErrBadAction = errors.New("action must be one of drop, busy (calendar.action option)")
+	ErrNoFile    = errors.New("calendar file not set (calendar.file option)")
+)
+
+// Config of the holiday and closure calendar, the file is read every upload session
+type Config struct {
+	File   string `yaml:"file"` // YAML list of closures, missing file means no closures
+	Action string `yaml:"action"`
+}
+
+func (c *Config) Check() error {
+	if c.Action == "" {
+		c.Action = DefaultAction
+	}
+
+	switch c.Action {
+	case ActionDrop, ActionBusy:
+	default:
+		return fmt.Errorf("%w: %s", ErrBadAction, c.Action)
+	}
+
+	if _, err := c.Load(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Load reads closures from the calendar file, empty calendar if the file is not set or not found
+func (c *Config) Load() (Calendar, error) {
+	if c.File == "" {
+		return Calendar{}, nil
+	}
+
+	return Load(c.File)
+}
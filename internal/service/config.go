@@ -11,8 +11,18 @@ import (
 
 	"gopkg.in/yaml.v2"
 
+	"prodoctorov/internal/service/calendar"
 	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/filials"
+	"prodoctorov/internal/service/filter"
+	"prodoctorov/internal/service/guard"
+	"prodoctorov/internal/service/hours"
+	"prodoctorov/internal/service/manual"
+	"prodoctorov/internal/service/names"
+	"prodoctorov/internal/service/overrides"
 	"prodoctorov/internal/service/prodoctorov"
+	"prodoctorov/internal/service/specialties"
+	"prodoctorov/internal/service/templates"
 )
 
 var (
@@ -34,6 +44,26 @@ type Config struct {
 
 	Prodoctorov prodoctorov.Config `yaml:"prodoctorov"`
 
+	Guard guard.Config `yaml:"guard"`
+
+	Manual manual.Config `yaml:"manual"`
+
+	Templates templates.Config `yaml:"templates"`
+
+	Filter filter.Config `yaml:"filter"`
+
+	Filials filials.Config `yaml:"filials"`
+
+	Calendar calendar.Config `yaml:"calendar"`
+
+	WorkingHours hours.Config `yaml:"working_hours"`
+
+	Overrides overrides.Config `yaml:"overrides"`
+
+	Names names.Config `yaml:"names"`
+
+	Specialties specialties.Config `yaml:"specialties"`
+
 	StartEveryMinutes int `yaml:"start_every_minutes"`
 	startEvery        time.Duration
 }
@@ -85,5 +115,45 @@ func LoadConfig(fileName string) (*Config, error) {
 		return nil, fmt.Errorf("bad prodoctorov config: %w", err)
 	}
 
+	if err := cfg.Guard.Check(); err != nil {
+		return nil, fmt.Errorf("bad guard config: %w", err)
+	}
+
+	if err := cfg.Manual.Check(); err != nil {
+		return nil, fmt.Errorf("bad manual config: %w", err)
+	}
+
+	if err := cfg.Templates.Check(); err != nil {
+		return nil, fmt.Errorf("bad templates config: %w", err)
+	}
+
+	if err := cfg.Filter.Check(); err != nil {
+		return nil, fmt.Errorf("bad filter config: %w", err)
+	}
+
+	if err := cfg.Filials.Check(); err != nil {
+		return nil, fmt.Errorf("bad filials config: %w", err)
+	}
+
+	if err := cfg.Calendar.Check(); err != nil {
+		return nil, fmt.Errorf("bad calendar config: %w", err)
+	}
+
+	if err := cfg.WorkingHours.Check(); err != nil {
+		return nil, fmt.Errorf("bad working hours config: %w", err)
+	}
+
+	if err := cfg.Overrides.Check(); err != nil {
+		return nil, fmt.Errorf("bad overrides config: %w", err)
+	}
+
+	if err := cfg.Names.Check(); err != nil {
+		return nil, fmt.Errorf("bad names config: %w", err)
+	}
+
+	if err := cfg.Specialties.Check(); err != nil {
+		return nil, fmt.Errorf("bad specialties config: %w", err)
+	}
+
 	return cfg, nil
 }
@@ -1,17 +1,91 @@
 package domino
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"prodoctorov/internal/service/dominocsv"
+)
 
 var (
-	ErrNoURL = errors.New("URL not found (url option)")
+	ErrNoURL           = errors.New("URL not found (url option)")
+	ErrBadHorizon      = errors.New("horizon must not be negative (horizon option)")
+	ErrBadThreshold    = errors.New("threshold must not be negative (max_rejected option)")
+	ErrTooManyRejected = errors.New("too many malformed rows, upload aborted")
+	ErrBadFilialColumn = errors.New("filial column must follow the mandatory columns (filial_column option)")
 )
 
+// RejectThreshold limits rows rejected on import, the schedule is not uploaded if any limit is exceeded;
+// zero means no limit
+type RejectThreshold struct {
+	Count   int     `yaml:"count"`
+	Percent float64 `yaml:"percent"` // of all data rows
+}
+
+// Horizon limits imported records by the meeting start time relative to the current time
+type Horizon struct {
+	Past   time.Duration `yaml:"past"`   // if empty, records from the first day of the current month are kept
+	Future time.Duration `yaml:"future"` // if empty, there is no upper bound
+}
+
+// from returns the earliest meeting start time allowed, timeNow is a wall clock time in UTC
+func (h *Horizon) from(timeNow time.Time) time.Time {
+	if h.Past == 0 {
+		return time.Date(timeNow.Year(), timeNow.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return timeNow.Add(-h.Past)
+}
+
+// isBeyond reports whether the start time is after the upper bound, timeNow is a wall clock time in UTC
+func (h *Horizon) isBeyond(timeNow time.Time, startTime time.Time) bool {
+	return h.Future != 0 && startTime.After(timeNow.Add(h.Future))
+}
+
+// Contains reports whether the start time is within the horizon, timeNow is a wall clock time in UTC
+func (h *Horizon) Contains(timeNow time.Time, startTime time.Time) bool {
+	return !startTime.Before(h.from(timeNow)) && !h.isBeyond(timeNow, startTime)
+}
+
 type Config struct {
 	URL      string `yaml:"url"`
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
 
 	RawScheduleCopyDir string `yaml:"raw_schedule_copy_dir"`
+
+	CSV dominocsv.Config `yaml:"csv"`
+
+	// TimeLayouts accepted layouts of the meeting start time, tried in order
+	TimeLayouts []string `yaml:"time_layouts"`
+
+	// FilialColumn optional zero-based index of the extra column with the filial code
+	FilialColumn int `yaml:"filial_column"`
+
+	Horizon Horizon `yaml:"horizon"`
+
+	Statuses Statuses `yaml:"statuses"`
+
+	// Conflicts policy of overlapped time cells of a doctor, see ResolveConflicts
+	Conflicts string `yaml:"conflicts"`
+
+	Durations Durations `yaml:"durations"`
+
+	Reshape Reshape `yaml:"reshape"`
+
+	// Midnight policy of time cells crossing midnight, see ApplyMidnightPolicy
+	Midnight string `yaml:"midnight"`
+
+	Publish PublishPolicy `yaml:"publish"`
+
+	// MergeSpecialties publishes a doctor with several specialties as one doctor, see MergeSpecialties
+	MergeSpecialties bool `yaml:"merge_specialties"`
+
+	// PrimarySpecialties specialty published for the merged schedule by external identifier or name of the doctor
+	PrimarySpecialties map[string]string `yaml:"primary_specialties"`
+
+	MaxRejected RejectThreshold `yaml:"max_rejected"`
 }
 
 func (c *Config) Check() error {
@@ -19,5 +93,53 @@ func (c *Config) Check() error {
 		return ErrNoURL
 	}
 
+	if err := c.CSV.Check(); err != nil {
+		return fmt.Errorf("bad csv dialect: %w", err)
+	}
+
+	if c.Horizon.Past < 0 || c.Horizon.Future < 0 {
+		return ErrBadHorizon
+	}
+
+	if err := c.Statuses.Check(); err != nil {
+		return err
+	}
+
+	if err := checkConflictPolicy(c.Conflicts); err != nil {
+		return err
+	}
+
+	if err := c.Durations.Check(); err != nil {
+		return err
+	}
+
+	if err := c.Reshape.Check(); err != nil {
+		return err
+	}
+
+	if err := checkMidnightPolicy(c.Midnight); err != nil {
+		return err
+	}
+
+	if err := c.Publish.Check(); err != nil {
+		return err
+	}
+
+	if c.MaxRejected.Count < 0 || c.MaxRejected.Percent < 0 {
+		return ErrBadThreshold
+	}
+
+	if c.FilialColumn != 0 && c.FilialColumn < MinFieldsCount {
+		return fmt.Errorf("%w: %d", ErrBadFilialColumn, c.FilialColumn)
+	}
+
 	return nil
 }
+
+func (c *Config) timeLayouts() []string {
+	if len(c.TimeLayouts) == 0 {
+		return []string{TimeLayout}
+	}
+
+	return c.TimeLayouts
+}
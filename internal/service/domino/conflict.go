@@ -0,0 +1,125 @@
+package domino
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// policies of overlapping time cells of a doctor
+const (
+	ConflictMerge     = "merge"     // cells are merged into one, busy if any of them is busy
+	ConflictKeepBusy  = "keep_busy" // busy cell wins, the first one if statuses are equal
+	ConflictKeepFirst = "keep_first"
+	ConflictReject    = "reject" // the whole doctor's schedule is not published
+
+	DefaultConflictPolicy = ConflictKeepBusy
+)
+
+// kinds of conflicts
+const (
+	ConflictDuplicate = "duplicate" // the same start time and duration
+	ConflictOverlap   = "overlap"
+)
+
+var (
+	ErrBadConflictPolicy   = errors.New("policy must be one of merge, keep_busy, keep_first, reject (conflicts option)")
+	ErrConflictingSchedule = errors.New("doctor's schedule has overlapping time cells")
+)
+
+func checkConflictPolicy(policy string) error {
+	switch policy {
+	case "", ConflictMerge, ConflictKeepBusy, ConflictKeepFirst, ConflictReject:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrBadConflictPolicy, policy)
+	}
+}
+
+// Conflict two time cells of the same doctor overlapped
+type Conflict struct {
+	Kind   string
+	First  TimeCell
+	Second TimeCell
+}
+
+func (c *Conflict) String() string {
+	return fmt.Sprintf("%s: %v and %v", c.Kind, c.First, c.Second)
+}
+
+type OrderCellsByDate TimeCells
+
+func (a OrderCellsByDate) Len() int      { return len(a) }
+func (a OrderCellsByDate) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a OrderCellsByDate) Less(i, j int) bool {
+	return a[i].StartTime.Before(a[j].StartTime)
+}
+
+func (c *TimeCell) EndTime() time.Time {
+	return c.StartTime.Add(c.Duration)
+}
+
+func (c *TimeCell) merged(other *TimeCell) *TimeCell {
+	result := *c
+
+	if other.EndTime().After(result.EndTime()) {
+		result.Duration = other.EndTime().Sub(result.StartTime)
+	}
+
+	result.Free = c.Free && other.Free
+
+	if result.Room == "" {
+		result.Room = other.Room
+	}
+
+	return &result
+}
+
+// ResolveConflicts removes duplicated and overlapped time cells according to the policy,
+// identical duplicates are always collapsed into one cell; every conflict found is returned
+func (s *DoctorSchedule) ResolveConflicts(policy string) ([]*Conflict, error) {
+	sort.Stable(OrderCellsByDate(s.Cells))
+
+	conflicts := make([]*Conflict, 0)
+	result := make(TimeCells, 0, len(s.Cells))
+
+	for _, cell := range s.Cells {
+		n := len(result)
+		if n == 0 || !cell.StartTime.Before(result[n-1].EndTime()) {
+			result = append(result, cell)
+
+			continue
+		}
+
+		last := result[n-1]
+
+		conflict := &Conflict{Kind: ConflictOverlap, First: *last, Second: *cell}
+		if last.StartTime.Equal(cell.StartTime) && last.Duration == cell.Duration {
+			conflict.Kind = ConflictDuplicate
+		}
+
+		conflicts = append(conflicts, conflict)
+
+		if conflict.Kind == ConflictDuplicate && *last == *cell {
+			continue
+		}
+
+		switch policy {
+		case ConflictReject:
+			return conflicts, fmt.Errorf("%w: %v", ErrConflictingSchedule, conflict)
+		case ConflictKeepFirst:
+			continue
+		case ConflictMerge:
+			result[n-1] = last.merged(cell)
+		default: // ConflictKeepBusy
+			if last.Free && !cell.Free {
+				result[n-1] = cell
+			}
+		}
+	}
+
+	s.Cells = result
+
+	return conflicts, nil
+}
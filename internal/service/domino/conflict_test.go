@@ -0,0 +1,117 @@
+package domino_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+)
+
+func cell(hour, minute int, duration time.Duration, free bool) *domino.TimeCell {
+	return &domino.TimeCell{
+		StartTime: time.Date(2021, 07, 01, hour, minute, 0, 0, time.UTC),
+		Duration:  duration,
+		Free:      free,
+		Room:      "12 кабинет",
+	}
+}
+
+func TestDoctorSchedule_ResolveConflicts(t *testing.T) {
+	conflicting := func() domino.TimeCells {
+		return domino.TimeCells{
+			cell(10, 0, 20*time.Minute, true),
+			cell(10, 0, 20*time.Minute, true), // identical duplicate
+			cell(10, 20, 30*time.Minute, true),
+			cell(10, 40, 20*time.Minute, false), // overlaps previous
+			cell(11, 0, 20*time.Minute, true),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		policy        string
+		want          domino.TimeCells
+		wantConflicts []string
+		wantErr       error
+	}{
+		{
+			name:   "keep busy",
+			policy: domino.ConflictKeepBusy,
+			want: domino.TimeCells{
+				cell(10, 0, 20*time.Minute, true),
+				cell(10, 40, 20*time.Minute, false),
+				cell(11, 0, 20*time.Minute, true),
+			},
+			wantConflicts: []string{domino.ConflictDuplicate, domino.ConflictOverlap},
+		},
+		{
+			name:   "default",
+			policy: "",
+			want: domino.TimeCells{
+				cell(10, 0, 20*time.Minute, true),
+				cell(10, 40, 20*time.Minute, false),
+				cell(11, 0, 20*time.Minute, true),
+			},
+			wantConflicts: []string{domino.ConflictDuplicate, domino.ConflictOverlap},
+		},
+		{
+			name:   "keep first",
+			policy: domino.ConflictKeepFirst,
+			want: domino.TimeCells{
+				cell(10, 0, 20*time.Minute, true),
+				cell(10, 20, 30*time.Minute, true),
+				cell(11, 0, 20*time.Minute, true),
+			},
+			wantConflicts: []string{domino.ConflictDuplicate, domino.ConflictOverlap},
+		},
+		{
+			name:   "merge",
+			policy: domino.ConflictMerge,
+			want: domino.TimeCells{
+				cell(10, 0, 20*time.Minute, true),
+				cell(10, 20, 40*time.Minute, false),
+				cell(11, 0, 20*time.Minute, true),
+			},
+			wantConflicts: []string{domino.ConflictDuplicate, domino.ConflictOverlap},
+		},
+		{
+			name:          "reject",
+			policy:        domino.ConflictReject,
+			want:          conflicting(),
+			wantConflicts: []string{domino.ConflictDuplicate, domino.ConflictOverlap},
+			wantErr:       domino.ErrConflictingSchedule,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &domino.DoctorSchedule{Cells: conflicting()}
+
+			conflicts, err := schedule.ResolveConflicts(tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResolveConflicts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			gotConflicts := make([]string, 0, len(conflicts))
+			for _, c := range conflicts {
+				gotConflicts = append(gotConflicts, c.Kind)
+			}
+
+			if !reflect.DeepEqual(gotConflicts, tt.wantConflicts) {
+				t.Errorf("ResolveConflicts() got conflicts = %v, want %v", gotConflicts, tt.wantConflicts)
+			}
+
+			if !reflect.DeepEqual(schedule.Cells, tt.want) {
+				for i, c := range schedule.Cells {
+					t.Errorf("Got item %d: %v", i, c)
+				}
+
+				t.Errorf("ResolveConflicts() got %d cells, want %d", len(schedule.Cells), len(tt.want))
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package domino
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -27,6 +28,7 @@ type Domino struct {
 	config    *Config
 	sessionID string
 	records   Records
+	report    *ImportReport
 }
 
 type LogError func(string)
@@ -76,13 +78,15 @@ func DownloadSchedule(ctx context.Context, config *Config, sessionID string, log
 		}
 	}
 
-	d.records, err = ImportRecords(body, time.Now(), func(message string) {
+	d.records, d.report, err = ImportRecords(body, config, time.Now(), func(message string) {
 		log(message)
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	d.WriteReport(log)
+
 	return d, nil
 }
 
@@ -94,6 +98,42 @@ func (d *Domino) dominoRawCopyFilename() string {
 	return filepath.Join(d.config.RawScheduleCopyDir, fmt.Sprintf("domino.raw.%s.csv", d.sessionID))
 }
 
+func (d *Domino) reportFilename(ext string) string {
+	return filepath.Join(d.config.RawScheduleCopyDir, fmt.Sprintf("domino.rejected.%s.%s", d.sessionID, ext))
+}
+
+// WriteReport saves the validation report next to the raw copy of the schedule, if the copy is required;
+// it is written again when findings of the schedule conversion are added to the report
+func (d *Domino) WriteReport(log LogError) {
+	if !d.isRequireDominoRawCopy() {
+		return
+	}
+
+	writers := map[string]func(io.Writer) error{
+		"csv":  d.report.WriteCSV,
+		"json": d.report.WriteJSON,
+	}
+
+	for ext, write := range writers {
+		var buf bytes.Buffer
+
+		if err := write(&buf); err != nil {
+			log(err.Error())
+
+			continue
+		}
+
+		if err := ioutil.WriteFile(d.reportFilename(ext), buf.Bytes(), 0600); err != nil {
+			log(err.Error())
+		}
+	}
+}
+
 func (d *Domino) Schedule() Records {
 	return d.records
 }
+
+// Report returns the validation report of the downloaded schedule
+func (d *Domino) Report() *ImportReport {
+	return d.report
+}
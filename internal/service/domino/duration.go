@@ -0,0 +1,118 @@
+package domino
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// duration inference strategies, used if Domino leaves duration empty
+const (
+	InferenceDay  = "day"  // the gap between the first two meetings of the day is used for the whole day
+	InferenceSlot = "slot" // the gap to the next meeting, see fixSlotDuration
+
+	DefaultInference = InferenceDay
+)
+
+var (
+	ErrBadInference    = errors.New("inference must be one of day, slot (durations.inference option)")
+	ErrBadDurationRule = errors.New("duration must be positive and not exceed max (durations option)")
+)
+
+// DurationRule the meeting duration used if Domino leaves it empty and the maximum one,
+// longer meetings get the default duration
+type DurationRule struct {
+	Default   time.Duration `yaml:"default"`
+	Max       time.Duration `yaml:"max"`
+	Inference string        `yaml:"inference"`
+}
+
+// DefaultDurationRule is used if no rule is configured
+var DefaultDurationRule = DurationRule{
+	Default:   DefaultMeetDuration,
+	Max:       MaxMeetDuration,
+	Inference: DefaultInference,
+}
+
+func (r *DurationRule) check() error {
+	switch r.Inference {
+	case "", InferenceDay, InferenceSlot:
+	default:
+		return fmt.Errorf("%w: %s", ErrBadInference, r.Inference)
+	}
+
+	if r.Default < 0 || r.Max < 0 || (r.Default != 0 && r.Max != 0 && r.Default > r.Max) {
+		return fmt.Errorf("%w: default %v, max %v", ErrBadDurationRule, r.Default, r.Max)
+	}
+
+	return nil
+}
+
+// overlay replaces the limits by the ones set in the other rule
+func (r DurationRule) overlay(other DurationRule) DurationRule {
+	if other.Default != 0 {
+		r.Default = other.Default
+	}
+
+	if other.Max != 0 {
+		r.Max = other.Max
+	}
+
+	if other.Inference != "" {
+		r.Inference = other.Inference
+	}
+
+	return r
+}
+
+// Durations duration rules, a doctor's rule overrides a specialty's one which overrides the global one,
+// only the limits set are overridden
+type Durations struct {
+	DurationRule `yaml:",inline"`
+
+	Specs   map[string]DurationRule `yaml:"specs"`   // by specialty
+	Doctors map[string]DurationRule `yaml:"doctors"` // by external doctor identifier or doctor's name
+}
+
+func (d *Durations) Check() error {
+	if err := d.DurationRule.check(); err != nil {
+		return err
+	}
+
+	for spec, rule := range d.Specs {
+		rule := rule
+		if err := rule.check(); err != nil {
+			return fmt.Errorf("%w: spec %s", err, spec)
+		}
+	}
+
+	for doctor, rule := range d.Doctors {
+		rule := rule
+		if err := rule.check(); err != nil {
+			return fmt.Errorf("%w: doctor %s", err, doctor)
+		}
+	}
+
+	return nil
+}
+
+// Rule returns the duration rule of the doctor
+func (d *Durations) Rule(spec string, name string, doctorID string) DurationRule {
+	rule := DefaultDurationRule.overlay(d.DurationRule)
+
+	if specRule, ok := d.Specs[spec]; ok {
+		rule = rule.overlay(specRule)
+	}
+
+	if doctorRule, ok := d.Doctors[doctorID]; ok && doctorID != "" {
+		rule = rule.overlay(doctorRule)
+	} else if doctorRule, ok := d.Doctors[name]; ok {
+		rule = rule.overlay(doctorRule)
+	}
+
+	if rule.Default > rule.Max {
+		rule.Default = rule.Max // limits of different levels may contradict
+	}
+
+	return rule
+}
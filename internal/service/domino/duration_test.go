@@ -0,0 +1,201 @@
+package domino_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+)
+
+func TestDurations_Rule(t *testing.T) {
+	durations := domino.Durations{
+		DurationRule: domino.DurationRule{Max: 90 * time.Minute},
+		Specs: map[string]domino.DurationRule{
+			"МРТ":      {Default: 60 * time.Minute, Max: 180 * time.Minute},
+			"Педиатр":  {Default: 15 * time.Minute, Inference: domino.InferenceSlot},
+			"Терапевт": {Max: 10 * time.Minute},
+		},
+		Doctors: map[string]domino.DurationRule{
+			"Петров Д.А.": {Default: 40 * time.Minute},
+			"8A3F00C1":    {Max: 240 * time.Minute},
+		},
+	}
+
+	if err := durations.Check(); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		spec     string
+		doctor   string
+		doctorID string
+		want     domino.DurationRule
+	}{
+		{
+			name: "global",
+			spec: "Уролог", doctor: "Петров Г.А.",
+			want: domino.DurationRule{Default: domino.DefaultMeetDuration, Max: 90 * time.Minute, Inference: domino.InferenceDay},
+		},
+		{
+			name: "specialty",
+			spec: "МРТ", doctor: "Петров Г.А.",
+			want: domino.DurationRule{Default: 60 * time.Minute, Max: 180 * time.Minute, Inference: domino.InferenceDay},
+		},
+		{
+			name: "specialty default only",
+			spec: "Педиатр", doctor: "Петров Г.А.",
+			want: domino.DurationRule{Default: 15 * time.Minute, Max: 90 * time.Minute, Inference: domino.InferenceSlot},
+		},
+		{
+			name: "contradicting levels",
+			spec: "Терапевт", doctor: "Петров Г.А.",
+			want: domino.DurationRule{Default: 10 * time.Minute, Max: 10 * time.Minute, Inference: domino.InferenceDay},
+		},
+		{
+			name: "doctor by name",
+			spec: "Хирург", doctor: "Петров Д.А.",
+			want: domino.DurationRule{Default: 40 * time.Minute, Max: 90 * time.Minute, Inference: domino.InferenceDay},
+		},
+		{
+			name: "doctor by id",
+			spec: "МРТ", doctor: "Петров Д.А.", doctorID: "8A3F00C1",
+			want: domino.DurationRule{Default: 60 * time.Minute, Max: 240 * time.Minute, Inference: domino.InferenceDay},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := durations.Rule(tt.spec, tt.doctor, tt.doctorID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurations_Check(t *testing.T) {
+	durations := domino.Durations{
+		Specs: map[string]domino.DurationRule{
+			"МРТ": {Default: 200 * time.Minute, Max: 180 * time.Minute},
+		},
+	}
+
+	if err := durations.Check(); !errors.Is(err, domino.ErrBadDurationRule) {
+		t.Errorf("Check() error = %v, want %v", err, domino.ErrBadDurationRule)
+	}
+}
+
+func TestRecords_CleanedWithRule(t *testing.T) {
+	rule := domino.DurationRule{Default: 60 * time.Minute, Max: 180 * time.Minute}
+
+	records := domino.Records{
+		&domino.Record{StartTime: time.Date(2021, 07, 01, 9, 0, 0, 0, time.UTC), Duration: 150 * time.Minute},
+		&domino.Record{StartTime: time.Date(2021, 07, 01, 12, 0, 0, 0, time.UTC), Duration: 240 * time.Minute},
+		&domino.Record{StartTime: time.Date(2021, 07, 02, 9, 0, 0, 0, time.UTC), Duration: 0},
+	}
+
+	want := []time.Duration{150 * time.Minute, 60 * time.Minute, 60 * time.Minute}
+
+	got := make([]time.Duration, 0, len(records))
+	for _, r := range records.Cleaned(rule) {
+		got = append(got, r.Duration)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Cleaned() = %v, want %v", got, want)
+	}
+}
+
+func TestRecords_CleanedSlotInference(t *testing.T) {
+	day := func(day, hour, minute int) time.Time {
+		return time.Date(2021, 07, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		starts    []time.Time
+		durations []time.Duration // explicit durations of the export, zero if empty
+		want      []time.Duration
+	}{
+		{
+			name:   "single slot",
+			starts: []time.Time{day(1, 9, 0)},
+			want:   []time.Duration{20 * time.Minute},
+		},
+		{
+			name:   "split shift",
+			starts: []time.Time{day(1, 9, 0), day(1, 9, 20), day(1, 9, 40), day(1, 14, 0), day(1, 14, 30), day(1, 15, 0)},
+			want: []time.Duration{
+				20 * time.Minute, 20 * time.Minute, 20 * time.Minute,
+				30 * time.Minute, 30 * time.Minute, 30 * time.Minute,
+			},
+		},
+		{
+			name:   "switch w/o break",
+			starts: []time.Time{day(1, 9, 0), day(1, 9, 20), day(1, 9, 40), day(1, 10, 10), day(1, 10, 40)},
+			want: []time.Duration{
+				20 * time.Minute, 20 * time.Minute, 30 * time.Minute, 30 * time.Minute, 30 * time.Minute,
+			},
+		},
+		{
+			name:   "long break",
+			starts: []time.Time{day(1, 9, 0), day(1, 10, 0), day(1, 10, 45), day(1, 12, 50)},
+			want: []time.Duration{
+				60 * time.Minute, 45 * time.Minute, 45 * time.Minute, 45 * time.Minute,
+			},
+		},
+		{
+			name:   "next day",
+			starts: []time.Time{day(1, 9, 0), day(1, 9, 30), day(2, 9, 0), day(2, 9, 15)},
+			want: []time.Duration{
+				30 * time.Minute, 30 * time.Minute, 15 * time.Minute, 15 * time.Minute,
+			},
+		},
+		{
+			name:   "duplicated start",
+			starts: []time.Time{day(1, 9, 0), day(1, 9, 0), day(1, 9, 15)},
+			want: []time.Duration{
+				15 * time.Minute, 15 * time.Minute, 15 * time.Minute,
+			},
+		},
+		{
+			name:      "explicit overlapping next",
+			starts:    []time.Time{day(1, 9, 0), day(1, 9, 20), day(1, 9, 40)},
+			durations: []time.Duration{30 * time.Minute, 15 * time.Minute, 30 * time.Minute},
+			want: []time.Duration{
+				20 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+			},
+		},
+	}
+
+	rule := domino.DurationRule{Default: 20 * time.Minute, Max: 60 * time.Minute, Inference: domino.InferenceSlot}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			records := make(domino.Records, 0, len(tt.starts))
+			for i, start := range tt.starts {
+				r := &domino.Record{StartTime: start}
+				if i < len(tt.durations) {
+					r.Duration = tt.durations[i]
+				}
+
+				records = append(records, r)
+			}
+
+			got := make([]time.Duration, 0, len(records))
+			for _, r := range records.Cleaned(rule) {
+				got = append(got, r.Duration)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cleaned() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package domino
+
+import (
+	"sort"
+	"time"
+)
+
+// DoctorStats counters of a doctor's schedule
+type DoctorStats struct {
+	Cells     int
+	FreeCells int
+	BusyCells int
+	First     time.Time // start of the first cell
+	Last      time.Time // end of the last cell
+}
+
+func (s *DoctorSchedule) Stats() DoctorStats {
+	stats := DoctorStats{
+		Cells: len(s.Cells),
+	}
+
+	for _, cell := range s.Cells {
+		if cell.Free {
+			stats.FreeCells++
+		} else {
+			stats.BusyCells++
+		}
+
+		if stats.First.IsZero() || cell.StartTime.Before(stats.First) {
+			stats.First = cell.StartTime
+		}
+
+		if cell.EndTime().After(stats.Last) {
+			stats.Last = cell.EndTime()
+		}
+	}
+
+	return stats
+}
+
+// GroupByDoctor returns schedule of every doctor of the records in order of the first appearance,
+// records don't have to be sorted; meeting durations are fixed according to the doctor's duration rule
+// matched by Domino values of the specialty and the name
+func (records Records) GroupByDoctor(config *Config) []*DoctorSchedule {
+	index := make(map[string]int)
+	groups := make([]Records, 0)
+
+	for _, r := range records {
+		id := r.ID()
+
+		i, ok := index[id]
+		if !ok {
+			i = len(groups)
+			index[id] = i
+			groups = append(groups, make(Records, 0))
+		}
+
+		groups[i] = append(groups[i], r)
+	}
+
+	result := make([]*DoctorSchedule, len(groups))
+
+	for i, doctorRecords := range groups {
+		first := doctorRecords[0]
+		first.KeepDominoValues()
+
+		doctorRecords = doctorRecords.Cleaned(config.Durations.Rule(first.DominoSpec, first.DominoName, first.DoctorID))
+
+		schedule := &DoctorSchedule{
+			ID:         first.DoctorID,
+			Spec:       first.Spec,
+			Name:       first.Name,
+			DominoSpec: first.DominoSpec,
+			Filial:     first.Filial,
+			Cells:      make(TimeCells, len(doctorRecords)),
+		}
+
+		for j, r := range doctorRecords {
+			schedule.Cells[j] = &TimeCell{
+				StartTime: r.StartTime,
+				Duration:  r.Duration,
+				Free:      r.Free,
+				Room:      r.Room,
+			}
+		}
+
+		result[i] = schedule
+	}
+
+	return result
+}
+
+// DoctorKey identifies the doctor regardless of the specialty
+func (s *DoctorSchedule) DoctorKey() string {
+	key := s.Name
+	if s.ID != "" {
+		key = s.ID
+	}
+
+	if s.Filial != "" {
+		return s.Filial + "/" + key
+	}
+
+	return key
+}
+
+// MergeSpecialties merges schedules of the same doctor with different specialties into one in order
+// of the first appearance; the primary specialty of the doctor (by external identifier or name) is published
+// if configured, otherwise the first one in sorted order; cells are not checked for conflicts
+func MergeSpecialties(schedules []*DoctorSchedule, primary map[string]string) []*DoctorSchedule {
+	index := make(map[string]*DoctorSchedule)
+	result := make([]*DoctorSchedule, 0, len(schedules))
+
+	for _, s := range schedules {
+		merged, ok := index[s.DoctorKey()]
+		if !ok {
+			merged = &DoctorSchedule{
+				ID:         s.ID,
+				Name:       s.Name,
+				DominoSpec: s.DominoSpec,
+				Filial:     s.Filial,
+				Cells:      make(TimeCells, 0, len(s.Cells)),
+			}
+			index[s.DoctorKey()] = merged
+			result = append(result, merged)
+		}
+
+		if !merged.hasSpec(s.Spec) {
+			merged.Specs = append(merged.Specs, s.Spec)
+		}
+
+		merged.Cells = append(merged.Cells, s.Cells...)
+		merged.Parts = append(merged.Parts, s)
+	}
+
+	for _, merged := range result {
+		sort.Strings(merged.Specs)
+		merged.Spec = merged.primarySpec(primary)
+	}
+
+	return result
+}
+
+func (s *DoctorSchedule) hasSpec(spec string) bool {
+	for _, known := range s.Specs {
+		if known == spec {
+			return true
+		}
+	}
+
+	return false
+}
+
+// primarySpec returns the configured specialty of the doctor if the doctor has it, the first one otherwise
+func (s *DoctorSchedule) primarySpec(primary map[string]string) string {
+	for _, key := range []string{s.ID, s.Name} {
+		if spec, ok := primary[key]; ok && key != "" && s.hasSpec(spec) {
+			return spec
+		}
+	}
+
+	return s.Specs[0]
+}
+
+// IsMerged reports whether the schedule is merged by MergeSpecialties
+func (s *DoctorSchedule) IsMerged() bool {
+	return len(s.Specs) != 0
+}
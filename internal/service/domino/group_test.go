@@ -0,0 +1,210 @@
+package domino_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+)
+
+func TestRecords_GroupByDoctor(t *testing.T) {
+	record := func(spec, name string, hour int, free bool) *domino.Record {
+		return &domino.Record{
+			Spec:      spec,
+			Name:      name,
+			StartTime: time.Date(2021, 07, 01, hour, 0, 0, 0, time.UTC),
+			Duration:  30 * time.Minute,
+			Free:      free,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		records domino.Records
+		want    []string
+	}{
+		{
+			name:    "empty",
+			records: domino.Records{},
+			want:    []string{},
+		},
+		{
+			name:    "single doctor",
+			records: domino.Records{record("Уролог", "Петров Г.А.", 10, true)},
+			want:    []string{"Уролог/Петров Г.А."},
+		},
+		{
+			name: "last doctor",
+			records: domino.Records{
+				record("Уролог", "Петров Г.А.", 10, true),
+				record("Хирург", "Петров Д.А.", 9, true),
+				record("Хирург", "Петров Д.А.", 10, false),
+			},
+			want: []string{"Уролог/Петров Г.А.", "Хирург/Петров Д.А."},
+		},
+		{
+			name: "not sorted",
+			records: domino.Records{
+				record("Хирург", "Петров Д.А.", 10, false),
+				record("Уролог", "Петров Г.А.", 10, true),
+				record("Хирург", "Петров Д.А.", 9, true),
+				record("ЛОР", "Иванов Л.Б.", 11, true),
+			},
+			want: []string{"Хирург/Петров Д.А.", "Уролог/Петров Г.А.", "ЛОР/Иванов Л.Б."},
+		},
+		{
+			name: "external id",
+			records: domino.Records{
+				record("Хирург", "Петров Д.А.", 9, true),
+				{
+					Spec:      "Хирург",
+					Name:      "Петров Д.А.",
+					StartTime: time.Date(2021, 07, 01, 9, 0, 0, 0, time.UTC),
+					DoctorID:  "8A3F00C1",
+				},
+			},
+			want: []string{"Хирург/Петров Д.А.", "Хирург/Петров Д.А."},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			cells := 0
+
+			for _, schedule := range tt.records.GroupByDoctor(&domino.Config{}) {
+				got = append(got, schedule.Spec+"/"+schedule.Name)
+				cells += schedule.Stats().Cells
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GroupByDoctor() got = %v, want %v", got, tt.want)
+			}
+
+			if cells != len(tt.records) {
+				t.Errorf("GroupByDoctor() got %d cells, want %d", cells, len(tt.records))
+			}
+		})
+	}
+}
+
+func TestRecords_GroupByDoctorDominoValues(t *testing.T) {
+	config := &domino.Config{
+		Durations: domino.Durations{
+			Specs: map[string]domino.DurationRule{"Терапевт участковый": {Default: 15 * time.Minute}},
+		},
+	}
+
+	records := domino.Records{{
+		Spec:       "Терапевт",
+		Name:       "Петров Е.Е.",
+		StartTime:  time.Date(2021, 07, 01, 9, 0, 0, 0, time.UTC),
+		DominoSpec: "Терапевт участковый",
+		DominoName: "Петров  Е.Е.",
+	}}
+
+	schedules := records.GroupByDoctor(config)
+	if len(schedules) != 1 {
+		t.Fatalf("GroupByDoctor() got %d schedules, want 1", len(schedules))
+	}
+
+	if got := schedules[0]; got.DominoSpec != "Терапевт участковый" || got.Cells[0].Duration != 15*time.Minute {
+		t.Errorf("GroupByDoctor() got = %+v, duration %v", got, got.Cells[0].Duration)
+	}
+}
+
+func TestDoctorSchedule_Stats(t *testing.T) {
+	schedule := &domino.DoctorSchedule{
+		Cells: domino.TimeCells{
+			cell(11, 0, 30*time.Minute, false),
+			cell(9, 0, 20*time.Minute, true),
+			cell(10, 0, 20*time.Minute, true),
+		},
+	}
+
+	want := domino.DoctorStats{
+		Cells:     3,
+		FreeCells: 2,
+		BusyCells: 1,
+		First:     time.Date(2021, 07, 01, 9, 0, 0, 0, time.UTC),
+		Last:      time.Date(2021, 07, 01, 11, 30, 0, 0, time.UTC),
+	}
+
+	if got := schedule.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats() got = %v, want %v", got, want)
+	}
+}
+
+func TestMergeSpecialties(t *testing.T) {
+	schedules := func() []*domino.DoctorSchedule {
+		return []*domino.DoctorSchedule{
+			{Spec: "Терапевт", Name: "Иванов И.И.", Cells: domino.TimeCells{cell(10, 0, 20*time.Minute, true)}},
+			{Spec: "Уролог", Name: "Петров П.П.", Cells: domino.TimeCells{cell(10, 0, 20*time.Minute, true)}},
+			{Spec: "Гастроэнтеролог", Name: "Иванов И.И.", Cells: domino.TimeCells{cell(12, 0, 20*time.Minute, false)}},
+			{Spec: "Терапевт", Name: "Иванов И.И.", Filial: "north", Cells: domino.TimeCells{}},
+		}
+	}
+
+	type doctor struct {
+		key   string
+		spec  string
+		specs []string
+		cells int
+		parts int
+	}
+
+	tests := []struct {
+		name    string
+		primary map[string]string
+		want    []doctor
+	}{
+		{
+			name: "sorted",
+			want: []doctor{
+				{key: "Иванов И.И.", spec: "Гастроэнтеролог", specs: []string{"Гастроэнтеролог", "Терапевт"}, cells: 2, parts: 2},
+				{key: "Петров П.П.", spec: "Уролог", specs: []string{"Уролог"}, cells: 1, parts: 1},
+				{key: "north/Иванов И.И.", spec: "Терапевт", specs: []string{"Терапевт"}, cells: 0, parts: 1},
+			},
+		},
+		{
+			name:    "primary",
+			primary: map[string]string{"Иванов И.И.": "Терапевт", "Петров П.П.": "Хирург"},
+			want: []doctor{
+				{key: "Иванов И.И.", spec: "Терапевт", specs: []string{"Гастроэнтеролог", "Терапевт"}, cells: 2, parts: 2},
+				{key: "Петров П.П.", spec: "Уролог", specs: []string{"Уролог"}, cells: 1, parts: 1},
+				{key: "north/Иванов И.И.", spec: "Терапевт", specs: []string{"Терапевт"}, cells: 0, parts: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			source := schedules()
+			got := domino.MergeSpecialties(source, tt.primary)
+
+			gotDoctors := make([]doctor, 0, len(got))
+			for _, s := range got {
+				gotDoctors = append(gotDoctors, doctor{
+					key:   s.DoctorKey(),
+					spec:  s.Spec,
+					specs: s.Specs,
+					cells: len(s.Cells),
+					parts: len(s.Parts),
+				})
+			}
+
+			if !reflect.DeepEqual(gotDoctors, tt.want) {
+				t.Errorf("MergeSpecialties() got = %v, want %v", gotDoctors, tt.want)
+			}
+
+			if len(source[0].Cells) != 1 || source[0].IsMerged() {
+				t.Errorf("MergeSpecialties() changed the source schedule")
+			}
+		})
+	}
+}
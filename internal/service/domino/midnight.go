@@ -0,0 +1,98 @@
+package domino
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// policies of time cells crossing midnight
+const (
+	MidnightSplit    = "split"    // the cell is split into parts of every date
+	MidnightTruncate = "truncate" // the cell ends at midnight
+	MidnightDrop     = "drop"     // the cell is not published
+
+	DefaultMidnightPolicy = MidnightSplit
+)
+
+var (
+	ErrBadMidnightPolicy = errors.New("policy must be one of split, truncate, drop (midnight option)")
+)
+
+func checkMidnightPolicy(policy string) error {
+	switch policy {
+	case "", MidnightSplit, MidnightTruncate, MidnightDrop:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrBadMidnightPolicy, policy)
+	}
+}
+
+// nextMidnight returns the start of the day after the time cell start
+func (c *TimeCell) nextMidnight() time.Time {
+	y, m, d := c.StartTime.Date()
+
+	return time.Date(y, m, d+1, 0, 0, 0, 0, c.StartTime.Location())
+}
+
+// CrossesMidnight reports whether the cell ends after the midnight, a cell ending at midnight does not cross it
+func (c *TimeCell) CrossesMidnight() bool {
+	return c.EndTime().After(c.nextMidnight())
+}
+
+// ApplyMidnightPolicy splits, truncates or drops time cells crossing midnight,
+// returns the number of such cells
+func (s *DoctorSchedule) ApplyMidnightPolicy(policy string) int {
+	if policy == "" {
+		policy = DefaultMidnightPolicy
+	}
+
+	crossing := 0
+	result := make(TimeCells, 0, len(s.Cells))
+
+	for _, cell := range s.Cells {
+		if !cell.CrossesMidnight() {
+			result = append(result, cell)
+
+			continue
+		}
+
+		crossing++
+
+		switch policy {
+		case MidnightSplit:
+			result = append(result, splitAtMidnight(cell)...)
+		case MidnightTruncate:
+			truncated := *cell
+			truncated.Duration = cell.nextMidnight().Sub(cell.StartTime)
+			result = append(result, &truncated)
+		}
+	}
+
+	s.Cells = result
+
+	return crossing
+}
+
+func splitAtMidnight(cell *TimeCell) TimeCells {
+	result := make(TimeCells, 0, 2)
+	end := cell.EndTime()
+
+	for part := *cell; ; {
+		midnight := part.nextMidnight()
+		if !end.After(midnight) {
+			part.Duration = end.Sub(part.StartTime)
+			result = append(result, &part)
+
+			break
+		}
+
+		first := part
+		first.Duration = midnight.Sub(part.StartTime)
+		result = append(result, &first)
+
+		part.StartTime = midnight
+	}
+
+	return result
+}
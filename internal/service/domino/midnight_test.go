@@ -0,0 +1,127 @@
+package domino_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+)
+
+func TestDoctorSchedule_ApplyMidnightPolicy(t *testing.T) {
+	nextDay := func(hour, minute int, duration time.Duration) *domino.TimeCell {
+		c := cell(hour, minute, duration, true)
+		c.StartTime = c.StartTime.AddDate(0, 0, 1)
+
+		return c
+	}
+
+	cells := func() domino.TimeCells {
+		return domino.TimeCells{
+			cell(22, 0, 2*time.Hour, true),
+			cell(23, 0, 3*time.Hour, true),
+		}
+	}
+
+	tests := []struct {
+		name         string
+		policy       string
+		want         domino.TimeCells
+		wantCrossing int
+	}{
+		{
+			name:   "split",
+			policy: domino.MidnightSplit,
+			want: domino.TimeCells{
+				cell(22, 0, 2*time.Hour, true),
+				cell(23, 0, time.Hour, true),
+				nextDay(0, 0, 2*time.Hour),
+			},
+			wantCrossing: 1,
+		},
+		{
+			name:   "default",
+			policy: "",
+			want: domino.TimeCells{
+				cell(22, 0, 2*time.Hour, true),
+				cell(23, 0, time.Hour, true),
+				nextDay(0, 0, 2*time.Hour),
+			},
+			wantCrossing: 1,
+		},
+		{
+			name:         "truncate",
+			policy:       domino.MidnightTruncate,
+			want:         domino.TimeCells{cell(22, 0, 2*time.Hour, true), cell(23, 0, time.Hour, true)},
+			wantCrossing: 1,
+		},
+		{
+			name:         "drop",
+			policy:       domino.MidnightDrop,
+			want:         domino.TimeCells{cell(22, 0, 2*time.Hour, true)},
+			wantCrossing: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &domino.DoctorSchedule{Cells: cells()}
+
+			if got := schedule.ApplyMidnightPolicy(tt.policy); got != tt.wantCrossing {
+				t.Errorf("ApplyMidnightPolicy() = %d, want %d", got, tt.wantCrossing)
+			}
+
+			if !reflect.DeepEqual(schedule.Cells, tt.want) {
+				for i, c := range schedule.Cells {
+					t.Errorf("Got item %d: %v", i, c)
+				}
+
+				t.Errorf("ApplyMidnightPolicy() got %d cells, want %d", len(schedule.Cells), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestDoctorSchedule_ApplyMidnightPolicy_MultiDay(t *testing.T) {
+	schedule := &domino.DoctorSchedule{Cells: domino.TimeCells{cell(12, 0, 36*time.Hour, false)}}
+	schedule.ApplyMidnightPolicy(domino.MidnightSplit)
+
+	var durations []time.Duration
+	for _, c := range schedule.Cells {
+		durations = append(durations, c.Duration)
+	}
+
+	if want := []time.Duration{12 * time.Hour, 24 * time.Hour}; !reflect.DeepEqual(durations, want) {
+		t.Errorf("ApplyMidnightPolicy() got durations = %v, want %v", durations, want)
+	}
+}
+
+func TestDoctorSchedule_ApplyMidnightPolicy_Collapse(t *testing.T) {
+	late := func(day, hour int, duration time.Duration) *domino.TimeCell {
+		return &domino.TimeCell{StartTime: time.Date(2021, 07, day, hour, 0, 0, 0, time.UTC), Duration: duration}
+	}
+
+	schedule := &domino.DoctorSchedule{Cells: domino.TimeCells{late(1, 22, time.Hour), late(1, 23, 2*time.Hour)}}
+	schedule.ApplyMidnightPolicy(domino.MidnightSplit)
+	schedule.ApplyPublishPolicy(&domino.PublishPolicy{Busy: domino.BusyCollapse})
+
+	want := domino.TimeCells{late(1, 22, 2*time.Hour), late(2, 0, time.Hour)}
+
+	if !reflect.DeepEqual(schedule.Cells, want) {
+		for i, c := range schedule.Cells {
+			t.Errorf("Got item %d: %v", i, c)
+		}
+
+		t.Errorf("ApplyPublishPolicy() got %d cells, want %d", len(schedule.Cells), len(want))
+	}
+}
+
+func TestConfig_CheckMidnight(t *testing.T) {
+	config := domino.Config{URL: "http://domino/", Midnight: "wrap"}
+	if err := config.Check(); !errors.Is(err, domino.ErrBadMidnightPolicy) {
+		t.Errorf("Check() error = %v, want %v", err, domino.ErrBadMidnightPolicy)
+	}
+}
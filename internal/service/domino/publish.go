@@ -0,0 +1,83 @@
+package domino
+
+import (
+	"errors"
+	"fmt"
+)
+
+// policies of busy time cells
+const (
+	BusyAsIs     = "as_is"    // busy cells are published
+	BusyDrop     = "drop"     // only free cells are published
+	BusyCollapse = "collapse" // contiguous busy cells are published as one busy cell
+
+	DefaultBusyPolicy = BusyAsIs
+)
+
+var (
+	ErrBadBusyPolicy = errors.New("policy must be one of as_is, drop, collapse (publish.busy option)")
+)
+
+// PublishPolicy what part of doctor's schedule is published
+type PublishPolicy struct {
+	Busy          string `yaml:"busy"`
+	DropFullyBusy bool   `yaml:"drop_fully_busy"` // doctors w/o free cells are not published
+}
+
+func (p *PublishPolicy) Check() error {
+	if p.Busy == "" {
+		p.Busy = DefaultBusyPolicy
+	}
+
+	switch p.Busy {
+	case BusyAsIs, BusyDrop, BusyCollapse:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrBadBusyPolicy, p.Busy)
+	}
+}
+
+// ApplyPublishPolicy removes or collapses busy time cells, the cells must be ordered;
+// returns false if the doctor must not be published
+func (s *DoctorSchedule) ApplyPublishPolicy(policy *PublishPolicy) bool {
+	switch policy.Busy {
+	case BusyDrop:
+		s.Cells = dropBusyCells(s.Cells)
+	case BusyCollapse:
+		s.Cells = collapseBusyCells(s.Cells)
+	}
+
+	return !policy.DropFullyBusy || s.Stats().FreeCells != 0
+}
+
+func dropBusyCells(cells TimeCells) TimeCells {
+	result := make(TimeCells, 0, len(cells))
+
+	for _, cell := range cells {
+		if cell.Free {
+			result = append(result, cell)
+		}
+	}
+
+	return result
+}
+
+// collapseBusyCells merges contiguous busy cells of the same day, the room of the first one is kept;
+// cells split at midnight stay split
+func collapseBusyCells(cells TimeCells) TimeCells {
+	result := make(TimeCells, 0, len(cells))
+
+	for _, cell := range cells {
+		n := len(result)
+		if n != 0 && !cell.Free && !result[n-1].Free && !cell.StartTime.After(result[n-1].EndTime()) &&
+			equalDay(cell.StartTime, result[n-1].StartTime) {
+			result[n-1] = result[n-1].merged(cell)
+
+			continue
+		}
+
+		result = append(result, cell)
+	}
+
+	return result
+}
@@ -0,0 +1,103 @@
+package domino_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+)
+
+func TestDoctorSchedule_ApplyPublishPolicy(t *testing.T) {
+	cells := func() domino.TimeCells {
+		return domino.TimeCells{
+			cell(10, 0, 20*time.Minute, false),
+			cell(10, 20, 20*time.Minute, false),
+			cell(10, 40, 20*time.Minute, true),
+			cell(11, 0, 20*time.Minute, false),
+			cell(11, 30, 20*time.Minute, false),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		policy      domino.PublishPolicy
+		cells       domino.TimeCells
+		want        domino.TimeCells
+		wantPublish bool
+	}{
+		{
+			name:        "as is",
+			policy:      domino.PublishPolicy{Busy: domino.BusyAsIs},
+			cells:       cells(),
+			want:        cells(),
+			wantPublish: true,
+		},
+		{
+			name:        "drop",
+			policy:      domino.PublishPolicy{Busy: domino.BusyDrop},
+			cells:       cells(),
+			want:        domino.TimeCells{cell(10, 40, 20*time.Minute, true)},
+			wantPublish: true,
+		},
+		{
+			name:   "collapse",
+			policy: domino.PublishPolicy{Busy: domino.BusyCollapse},
+			cells:  cells(),
+			want: domino.TimeCells{
+				cell(10, 0, 40*time.Minute, false),
+				cell(10, 40, 20*time.Minute, true),
+				cell(11, 0, 20*time.Minute, false),
+				cell(11, 30, 20*time.Minute, false),
+			},
+			wantPublish: true,
+		},
+		{
+			name:        "fully busy",
+			policy:      domino.PublishPolicy{Busy: domino.BusyCollapse, DropFullyBusy: true},
+			cells:       domino.TimeCells{cell(10, 0, 20*time.Minute, false), cell(10, 20, 20*time.Minute, false)},
+			want:        domino.TimeCells{cell(10, 0, 40*time.Minute, false)},
+			wantPublish: false,
+		},
+		{
+			name:        "empty after drop",
+			policy:      domino.PublishPolicy{Busy: domino.BusyDrop, DropFullyBusy: true},
+			cells:       domino.TimeCells{cell(10, 0, 20*time.Minute, false)},
+			want:        domino.TimeCells{},
+			wantPublish: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &domino.DoctorSchedule{Cells: tt.cells}
+
+			if got := schedule.ApplyPublishPolicy(&tt.policy); got != tt.wantPublish {
+				t.Errorf("ApplyPublishPolicy() = %v, want %v", got, tt.wantPublish)
+			}
+
+			if !reflect.DeepEqual(schedule.Cells, tt.want) {
+				for i, c := range schedule.Cells {
+					t.Errorf("Got item %d: %v", i, c)
+				}
+
+				t.Errorf("ApplyPublishPolicy() got %d cells, want %d", len(schedule.Cells), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestPublishPolicy_Check(t *testing.T) {
+	policy := domino.PublishPolicy{}
+	if err := policy.Check(); err != nil || policy.Busy != domino.DefaultBusyPolicy {
+		t.Errorf("Check() error = %v, busy = %s", err, policy.Busy)
+	}
+
+	policy = domino.PublishPolicy{Busy: "hide"}
+	if err := policy.Check(); !errors.Is(err, domino.ErrBadBusyPolicy) {
+		t.Errorf("Check() error = %v, want %v", err, domino.ErrBadBusyPolicy)
+	}
+}
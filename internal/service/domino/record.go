@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 	"time"
 
 	"prodoctorov/internal/service/dominocsv"
@@ -23,6 +24,7 @@ const (
 	IdxDuration  = 3
 	IdxFree      = 4
 	IdxRoom      = 5
+	IdxDoctorID  = 6 // optional external doctor identifier, e.g. Domino document UNID or staff number
 
 	TimeLayout = "2.1.06 15:04:05"
 
@@ -34,9 +36,40 @@ const (
 var (
 	ErrMalformedRecord = errors.New("malformed record")
 	ErrExpiredRecord   = errors.New("expired record")
+	ErrBeyondHorizon   = errors.New("record beyond the schedule horizon")
 	ErrMandatoryField  = errors.New("mandatory field is empty")
+	ErrBadTime         = errors.New("failed to decode starting date field")
+	ErrBadDuration     = errors.New("failed to decode interval field")
 )
 
+// field names of the Domino export header
+const (
+	FieldSpec      = "spec"
+	FieldName      = "name"
+	FieldStartTime = "cell"
+	FieldDuration  = "duration"
+	FieldFree      = "free"
+)
+
+// FieldError describes rejection of a record caused by a single field
+type FieldError struct {
+	Field string
+	Err   error // one of module errors, the rejection reason
+	Cause error // optional underlying error
+}
+
+func (e *FieldError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%v: %s: %v", e.Err, e.Field, e.Cause)
+	}
+
+	return fmt.Sprintf("%v: %s", e.Err, e.Field)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
 type Record struct {
 	Spec      string
 	Name      string
@@ -44,10 +77,42 @@ type Record struct {
 	Duration  time.Duration
 	Free      bool
 	Room      string
+	DoctorID  string
+
+	FilialCode string // raw value of the optional filial column, see Config.FilialColumn
+	Filial     string // filial identifier the record is published in, empty for the single filial
+
+	// Domino values of the specialty and the name kept when they are mapped to prodoctorov ones,
+	// duration and reshape rules are matched by Domino values
+	DominoSpec string
+	DominoName string
+
+	Generated bool // free slot of a weekly template, see templates.Generate
 }
 
+// KeepDominoValues saves the specialty and the name before they are mapped, the first mapping wins
+func (r *Record) KeepDominoValues() {
+	if r.DominoSpec == "" {
+		r.DominoSpec = r.Spec
+	}
+
+	if r.DominoName == "" {
+		r.DominoName = r.Name
+	}
+}
+
+// ID identifies doctor's schedule, the external doctor identifier is preferred to the name if present
 func (r *Record) ID() string {
-	return fmt.Sprintf("%s/%s", r.Spec, r.Name)
+	id := fmt.Sprintf("%s/%s", r.Spec, r.Name)
+	if r.DoctorID != "" {
+		id = fmt.Sprintf("%s/%s", r.Spec, r.DoctorID)
+	}
+
+	if r.Filial != "" {
+		return fmt.Sprintf("%s/%s", r.Filial, id)
+	}
+
+	return id
 }
 
 type Records []*Record
@@ -68,7 +133,29 @@ func (a OrderByDate) Less(i, j int) bool {
 	return a[i].StartTime.Before(a[j].StartTime)
 }
 
-func NewRecord(record []string, timeNow time.Time) (*Record, error) {
+func parseTime(value string, layouts []string) (time.Time, error) {
+	var firstErr error
+
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, nil
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return time.Time{}, firstErr
+}
+
+// WallClock converts time to UTC keeping the wall clock, Domino exports the local time w/o time zone
+func WallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+func NewRecord(record []string, config *Config, timeNow time.Time) (*Record, error) {
 	if len(record) < MinFieldsCount {
 		return nil, fmt.Errorf("%w: %s", ErrMalformedRecord, "too short record")
 	}
@@ -76,90 +163,127 @@ func NewRecord(record []string, timeNow time.Time) (*Record, error) {
 	result := &Record{
 		Spec: record[IdxSpec],
 		Name: record[IdxName],
-		Free: record[IdxFree] != BusyCode, // empty field equals free time
 		Room: record[IdxRoom],
+
+		DoctorID: strings.TrimSpace(record[IdxDoctorID]),
+	}
+
+	if config.FilialColumn != 0 && len(record) > config.FilialColumn {
+		result.FilialCode = strings.TrimSpace(record[config.FilialColumn])
 	}
 
 	if result.Spec == "" {
-		return nil, fmt.Errorf("%w: %s", ErrMandatoryField, "spec")
+		return nil, &FieldError{Field: FieldSpec, Err: ErrMandatoryField}
 	}
 
 	if result.Name == "" {
-		return nil, fmt.Errorf("%w: %s", ErrMandatoryField, "name")
+		return nil, &FieldError{Field: FieldName, Err: ErrMandatoryField}
 	}
 
 	var err error
 
-	result.StartTime, err = time.Parse(TimeLayout, record[IdxStartTime])
+	result.StartTime, err = parseTime(record[IdxStartTime], config.timeLayouts())
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode starting date field: %w", err)
+		return nil, &FieldError{Field: FieldStartTime, Err: ErrBadTime, Cause: err}
 	}
 
 	duration := record[IdxDuration]
 	if duration != "" {
 		result.Duration, err = time.ParseDuration(fmt.Sprintf("%sm", duration))
 		if err != nil {
-			return nil, fmt.Errorf("failed to decode interval field: %w", err)
+			return nil, &FieldError{Field: FieldDuration, Err: ErrBadDuration, Cause: err}
 		}
 	}
 
-	monthBegin := time.Date(timeNow.Year(), timeNow.Month(), 1, 0, 0, 0, 0, time.UTC)
+	timeNow = WallClock(timeNow)
 
-	if result.StartTime.Before(monthBegin) {
+	if result.StartTime.Before(config.Horizon.from(timeNow)) {
 		return nil, ErrExpiredRecord
 	}
 
+	if config.Horizon.isBeyond(timeNow, result.StartTime) {
+		return nil, ErrBeyondHorizon
+	}
+
+	status, err := config.Statuses.resolve(record[IdxFree])
+	if err != nil {
+		return nil, &FieldError{Field: FieldFree, Err: err}
+	}
+
+	if status == StatusDrop {
+		return nil, ErrDroppedStatus
+	}
+
+	result.Free = status == StatusFree
+
 	return result, nil
 }
 
 type LogMalformedRecord func(string)
 
-func ImportRecords(body []byte, timeNow time.Time, log LogMalformedRecord) (Records, error) {
-	csv, err := dominocsv.NewReader(bytes.NewReader(body))
+// ImportRecords decodes Domino export, rows failed to decode are skipped and collected into the report
+func ImportRecords(body []byte, config *Config, timeNow time.Time, log LogMalformedRecord) (Records, *ImportReport, error) {
+	csv, err := dominocsv.NewReader(bytes.NewReader(body), &config.CSV)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	records := make(CsvRecords, 0)
+	report := NewImportReport()
+	result := make(Records, 0)
+
+	if _, err := csv.Read(); err != nil && !errors.Is(err, io.EOF) { // skip header
+		return nil, nil, err
+	}
 
 	for {
-		record, err := csv.Read()
+		r, err := csv.Read()
 		if errors.Is(err, io.EOF) {
 			break
 		}
 
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		records = append(records, record)
-	}
-
-	records = records[1:] // skip header
-
-	result := make(Records, len(records))
+		report.Total++
+		report.DoctorDays.addRow(r, config)
 
-	i := 0
+		rec, err := NewRecord(r, config, timeNow)
+		if err == nil || errors.Is(err, ErrDroppedStatus) || errors.Is(err, ErrUnknownStatus) {
+			countUnknownStatus(report, config, r)
+		}
 
-	for _, r := range records {
-		rec, err := NewRecord(r, timeNow)
 		if err != nil {
-			if !errors.Is(err, ErrExpiredRecord) {
-				log(fmt.Sprintf("skip malformed record: %v: %v", err, r))
+			switch {
+			case errors.Is(err, ErrExpiredRecord):
+				report.DroppedPast++
+			case errors.Is(err, ErrBeyondHorizon):
+				report.DroppedFuture++
+			case errors.Is(err, ErrDroppedStatus):
+				report.DroppedStatus++
+			default:
+				report.Reject(csv.Line(), r, err)
+				log(fmt.Sprintf("skip malformed record: line %d: %v: %v", csv.Line(), err, r))
 			}
 
 			continue
 		}
 
-		result[i] = rec
-		i++
+		result = append(result, rec)
 	}
 
-	result = result[:i] // fix size, some records might be skipped
+	report.Imported = len(result)
 
 	sort.Sort(OrderByID(result))
 
-	return result, nil
+	return result, report, nil
+}
+
+// countUnknownStatus counts the status code of the row within the horizon if it is not listed in the mapping
+func countUnknownStatus(report *ImportReport, config *Config, record []string) {
+	if len(record) > IdxFree && !config.Statuses.isKnown(record[IdxFree]) {
+		report.UnknownStatuses[normalizeStatusCode(record[IdxFree])]++
+	}
 }
 
 func equalDay(d1 time.Time, d2 time.Time) bool {
@@ -173,8 +297,8 @@ func equalDay(d1 time.Time, d2 time.Time) bool {
 	return true
 }
 
-// Cleaned function fixes empty meeting duration time
-func (records Records) Cleaned() Records {
+// Cleaned function fixes empty meeting duration time according to the duration rule of the doctor
+func (records Records) Cleaned(rule DurationRule) Records {
 	recordsCount := len(records)
 	if recordsCount == 0 {
 		return records
@@ -183,7 +307,7 @@ func (records Records) Cleaned() Records {
 	if recordsCount == 1 {
 		r := records[0]
 		if r.Duration == 0 {
-			r.Duration = DefaultMeetDuration
+			r.Duration = rule.Default
 		}
 
 		return records
@@ -191,10 +315,14 @@ func (records Records) Cleaned() Records {
 
 	sort.Sort(OrderByDate(records))
 
-	return fixDuration(recordsCount, records)
+	if rule.Inference == InferenceSlot {
+		return fixSlotDuration(recordsCount, records, rule)
+	}
+
+	return fixDuration(recordsCount, records, rule)
 }
 
-func fixDuration(recordsCount int, records Records) Records {
+func fixDuration(recordsCount int, records Records, rule DurationRule) Records {
 	result := make(Records, recordsCount)
 
 	var (
@@ -220,14 +348,14 @@ func fixDuration(recordsCount int, records Records) Records {
 
 		if r.Duration == 0 {
 			if actualDuration == 0 {
-				r.Duration = DefaultMeetDuration
+				r.Duration = rule.Default
 			} else {
 				r.Duration = actualDuration
 			}
 		}
 
-		if r.Duration > MaxMeetDuration {
-			r.Duration = DefaultMeetDuration
+		if r.Duration > rule.Max {
+			r.Duration = rule.Default
 		}
 
 		lastDate = r.StartTime
@@ -237,62 +365,83 @@ func fixDuration(recordsCount int, records Records) Records {
 	return result
 }
 
-type TimeCell struct {
-	StartTime time.Time
-	Duration  time.Duration
-	Free      bool
-	Room      string
-}
+// gapToNext returns time to the next meeting of the same day, zero if it is the last one
+func gapToNext(records Records, i int) time.Duration {
+	for _, next := range records[i+1:] {
+		if !equalDay(records[i].StartTime, next.StartTime) {
+			break
+		}
 
-type TimeCells []*TimeCell
+		if next.StartTime.After(records[i].StartTime) {
+			return next.StartTime.Sub(records[i].StartTime)
+		}
+	}
 
-type DoctorSchedule struct {
-	Spec  string
-	Name  string
-	Cells TimeCells
+	return 0
 }
 
-type DoctorScheduleFetcher func(*DoctorSchedule)
-
-// LoadDoctorSchedule external function is called every time one doctor's schedule is completed and ready to be uploaded
-func (records Records) LoadDoctorSchedule(fetcher DoctorScheduleFetcher) {
-	var lastID, lastSpec, lastName string
+// fixSlotDuration infers duration of every meeting from the gap to the next meeting, a gap longer than
+// max duration is a break, then the previous meeting's duration (or the default one) is used,
+// explicit duration is capped by the gap to the next meeting
+func fixSlotDuration(recordsCount int, records Records, rule DurationRule) Records {
+	result := make(Records, recordsCount)
 
-	doctorRecords := make(Records, 0)
+	var lastDuration time.Duration
 
-	for _, r := range records {
-		if lastID == "" {
-			lastID = r.ID()
-			lastSpec = r.Spec
-			lastName = r.Name
+	for i, r := range records {
+		if i > 0 && !equalDay(records[i-1].StartTime, r.StartTime) {
+			lastDuration = 0
 		}
 
-		if r.ID() != lastID {
-			doctorRecords = doctorRecords.Cleaned()
+		gap := gapToNext(records, i)
 
-			schedule := &DoctorSchedule{
-				Spec:  lastSpec,
-				Name:  lastName,
-				Cells: make(TimeCells, len(doctorRecords)),
+		inferred := gap
+		if gap == 0 || gap > rule.Max {
+			inferred = lastDuration
+			if inferred == 0 {
+				inferred = rule.Default
 			}
+		}
 
-			for i, rr := range doctorRecords {
-				schedule.Cells[i] = &TimeCell{
-					StartTime: rr.StartTime,
-					Duration:  rr.Duration,
-					Free:      rr.Free,
-					Room:      rr.Room,
-				}
-			}
+		lastDuration = inferred
+
+		if r.Duration == 0 {
+			r.Duration = inferred
+		}
 
-			fetcher(schedule)
+		if r.Duration > rule.Max {
+			r.Duration = rule.Default
+		}
 
-			lastID = r.ID()
-			lastSpec = r.Spec
-			lastName = r.Name
-			doctorRecords = make(Records, 0)
+		if gap != 0 && r.Duration > gap { // explicit duration overlapping the next meeting
+			r.Duration = gap
 		}
 
-		doctorRecords = append(doctorRecords, r)
+		result[i] = r
 	}
+
+	return result
+}
+
+type TimeCell struct {
+	StartTime time.Time
+	Duration  time.Duration
+	Free      bool
+	Room      string
+}
+
+type TimeCells []*TimeCell
+
+type DoctorSchedule struct {
+	ID         string // external doctor identifier, empty if not exported
+	Spec       string
+	Name       string
+	DominoSpec string // specialty of the export before mapping, see Record.DominoSpec
+	Filial     string
+	Cells      TimeCells
+
+	// Specs sorted specialties of the schedule merged from Parts, schedules of the specialties;
+	// empty if the schedule is not merged, see MergeSpecialties
+	Specs []string
+	Parts []*DoctorSchedule
 }
@@ -11,6 +11,7 @@ import (
 func TestNewDominoRecord(t *testing.T) {
 	type args struct {
 		record  []string
+		config  *domino.Config
 		timeNow time.Time
 	}
 
@@ -24,6 +25,7 @@ func TestNewDominoRecord(t *testing.T) {
 			name: "record 1",
 			args: args{
 				record:  []string{"Гастроэнтеролог", "Иванов Е.А.", "1.4.20 10:00:00", "30", "free", "", ""},
+				config:  &domino.Config{},
 				timeNow: time.Date(2020, 04, 10, 0, 0, 0, 0, time.UTC),
 			},
 			want: &domino.Record{
@@ -40,6 +42,7 @@ func TestNewDominoRecord(t *testing.T) {
 			name: "record 2",
 			args: args{
 				record:  []string{"Дерматолог", "Иванов Е.С.", "1.7.21 10:00:00", "20", "busy", "6 кабинет", ""},
+				config:  &domino.Config{},
 				timeNow: time.Date(2021, 07, 05, 0, 0, 0, 0, time.UTC),
 			},
 			want: &domino.Record{
@@ -56,6 +59,7 @@ func TestNewDominoRecord(t *testing.T) {
 			name: "empty duration",
 			args: args{
 				record:  []string{"Дерматолог", "Иванов Е.С.", "1.7.21 10:30:00", "", "busy", "6 кабинет", ""},
+				config:  &domino.Config{},
 				timeNow: time.Date(2021, 07, 05, 0, 0, 0, 0, time.UTC),
 			},
 			want: &domino.Record{
@@ -68,10 +72,119 @@ func TestNewDominoRecord(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "external doctor id",
+			args: args{
+				record:  []string{"Дерматолог", "Иванов Е.С.", "1.7.21 10:00:00", "20", "busy", "6 кабинет", " 8A3F00C1 "},
+				config:  &domino.Config{},
+				timeNow: time.Date(2021, 07, 05, 0, 0, 0, 0, time.UTC),
+			},
+			want: &domino.Record{
+				Spec:      "Дерматолог",
+				Name:      "Иванов Е.С.",
+				StartTime: time.Date(2021, 07, 01, 10, 0, 0, 0, time.UTC),
+				Free:      false,
+				Duration:  20 * time.Minute,
+				Room:      "6 кабинет",
+				DoctorID:  "8A3F00C1",
+			},
+			wantErr: false,
+		},
+		{
+			name: "filial column",
+			args: args{
+				record:  []string{"Дерматолог", "Иванов Е.С.", "1.7.21 10:00:00", "20", "busy", "6 кабинет", "", " ЮГ "},
+				config:  &domino.Config{FilialColumn: 7},
+				timeNow: time.Date(2021, 07, 05, 0, 0, 0, 0, time.UTC),
+			},
+			want: &domino.Record{
+				Spec:       "Дерматолог",
+				Name:       "Иванов Е.С.",
+				StartTime:  time.Date(2021, 07, 01, 10, 0, 0, 0, time.UTC),
+				Free:       false,
+				Duration:   20 * time.Minute,
+				Room:       "6 кабинет",
+				FilialCode: "ЮГ",
+			},
+			wantErr: false,
+		},
+		{
+			name: "four-digit year",
+			args: args{
+				record: []string{"Дерматолог", "Иванов Е.С.", "01.07.2021 10:00", "20", "busy", "6 кабинет", ""},
+				config: &domino.Config{
+					TimeLayouts: []string{domino.TimeLayout, "02.01.2006 15:04"},
+				},
+				timeNow: time.Date(2021, 07, 05, 0, 0, 0, 0, time.UTC),
+			},
+			want: &domino.Record{
+				Spec:      "Дерматолог",
+				Name:      "Иванов Е.С.",
+				StartTime: time.Date(2021, 07, 01, 10, 0, 0, 0, time.UTC),
+				Free:      false,
+				Duration:  20 * time.Minute,
+				Room:      "6 кабинет",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown time layout",
+			args: args{
+				record:  []string{"Дерматолог", "Иванов Е.С.", "01.07.2021 10:00", "20", "busy", "6 кабинет", ""},
+				config:  &domino.Config{},
+				timeNow: time.Date(2021, 07, 05, 0, 0, 0, 0, time.UTC),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "within horizon",
+			args: args{
+				record: []string{"Дерматолог", "Иванов Е.С.", "5.7.21 9:40:00", "20", "busy", "6 кабинет", ""},
+				config: &domino.Config{
+					Horizon: domino.Horizon{Past: time.Hour, Future: 30 * 24 * time.Hour},
+				},
+				timeNow: time.Date(2021, 07, 05, 10, 30, 0, 0, time.UTC),
+			},
+			want: &domino.Record{
+				Spec:      "Дерматолог",
+				Name:      "Иванов Е.С.",
+				StartTime: time.Date(2021, 07, 05, 9, 40, 0, 0, time.UTC),
+				Free:      false,
+				Duration:  20 * time.Minute,
+				Room:      "6 кабинет",
+			},
+			wantErr: false,
+		},
+		{
+			name: "before horizon",
+			args: args{
+				record: []string{"Дерматолог", "Иванов Е.С.", "5.7.21 9:20:00", "20", "busy", "6 кабинет", ""},
+				config: &domino.Config{
+					Horizon: domino.Horizon{Past: time.Hour, Future: 30 * 24 * time.Hour},
+				},
+				timeNow: time.Date(2021, 07, 05, 10, 30, 0, 0, time.UTC),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "after horizon",
+			args: args{
+				record: []string{"Дерматолог", "Иванов Е.С.", "5.8.21 10:40:00", "20", "busy", "6 кабинет", ""},
+				config: &domino.Config{
+					Horizon: domino.Horizon{Past: time.Hour, Future: 30 * 24 * time.Hour},
+				},
+				timeNow: time.Date(2021, 07, 05, 10, 30, 0, 0, time.UTC),
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "record expired",
 			args: args{
 				record:  []string{"Гастроэнтеролог", "Иванов Е.А.", "1.4.20 10:00:00", "30", "free", "", ""},
+				config:  &domino.Config{},
 				timeNow: time.Date(2021, 07, 05, 0, 0, 0, 0, time.UTC),
 			},
 			want:    nil,
@@ -83,7 +196,7 @@ func TestNewDominoRecord(t *testing.T) {
 		tt := tt
 
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := domino.NewRecord(tt.args.record, tt.args.timeNow)
+			got, err := domino.NewRecord(tt.args.record, tt.args.config, tt.args.timeNow)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewRecord() error = %v, wantErr %v", err, tt.wantErr)
 
@@ -262,7 +375,7 @@ func TestRecords_Cleaned(t *testing.T) {
 		tt := tt
 
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.records.Cleaned(); !reflect.DeepEqual(got, tt.want) {
+			if got := tt.records.Cleaned(domino.DefaultDurationRule); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Cleaned() = %v, want %v", got, tt.want)
 
 				for i, r := range got {
@@ -0,0 +1,227 @@
+package domino
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rejection a row of the Domino export skipped on import
+type Rejection struct {
+	Line   int      `json:"line"`
+	Field  string   `json:"field"`
+	Reason string   `json:"reason"`
+	Error  string   `json:"error"`
+	Fields []string `json:"fields"`
+}
+
+// ErrOutOfHours reason of the time cells reported outside working hours
+var ErrOutOfHours = errors.New("time cell outside working hours")
+
+// OutOfHoursCell a time cell outside working hours of the doctor's filial, see hours.Apply
+type OutOfHoursCell struct {
+	Spec      string    `json:"spec"`
+	Name      string    `json:"name"`
+	DoctorID  string    `json:"doctor_id,omitempty"`
+	Filial    string    `json:"filial,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Action    string    `json:"action"` // drop or flag, see hours.Config
+}
+
+// ImportReport validation report of the Domino export, see ImportRecords
+type ImportReport struct {
+	Total         int          `json:"total"` // data rows, w/o header
+	Imported      int          `json:"imported"`
+	DroppedPast   int          `json:"dropped_past"`   // before the schedule horizon
+	DroppedFuture int          `json:"dropped_future"` // after the schedule horizon
+	DroppedStatus int          `json:"dropped_status"` // by the status code mapping
+	Rejected      []*Rejection `json:"rejected"`
+
+	UnknownStatuses map[string]int `json:"unknown_statuses"` // normalized code -> rows count
+
+	// OutOfHours time cells outside working hours, found after the import, see Domino.WriteReport
+	OutOfHours []*OutOfHoursCell `json:"out_of_hours"`
+
+	// DoctorDays days of doctors of all data rows with valid start time, including dropped and rejected ones
+	DoctorDays DoctorDays `json:"-"`
+}
+
+func NewImportReport() *ImportReport {
+	return &ImportReport{
+		Rejected:        make([]*Rejection, 0),
+		UnknownStatuses: make(map[string]int),
+		OutOfHours:      make([]*OutOfHoursCell, 0),
+		DoctorDays:      make(DoctorDays),
+	}
+}
+
+// DoctorDay a day of the doctor present in the export
+type DoctorDay struct {
+	Spec     string
+	Name     string
+	DoctorID string
+	Date     time.Time // midnight of the day
+}
+
+type DoctorDays map[DoctorDay]struct{}
+
+// Add adds the day of the record
+func (d DoctorDays) Add(r *Record) {
+	y, m, day := r.StartTime.Date()
+
+	d[DoctorDay{
+		Spec:     r.Spec,
+		Name:     r.Name,
+		DoctorID: r.DoctorID,
+		Date:     time.Date(y, m, day, 0, 0, 0, 0, time.UTC),
+	}] = struct{}{}
+}
+
+// addRow adds the day of the raw row if the doctor and the start time are valid
+func (d DoctorDays) addRow(row []string, config *Config) {
+	if len(row) < MinFieldsCount || row[IdxSpec] == "" || row[IdxName] == "" {
+		return
+	}
+
+	startTime, err := parseTime(row[IdxStartTime], config.timeLayouts())
+	if err != nil {
+		return
+	}
+
+	d.Add(&Record{
+		Spec:      row[IdxSpec],
+		Name:      row[IdxName],
+		DoctorID:  strings.TrimSpace(row[IdxDoctorID]),
+		StartTime: startTime,
+	})
+}
+
+// Reject appends the row rejected by NewRecord to the report
+func (r *ImportReport) Reject(line int, fields []string, err error) {
+	rejection := &Rejection{
+		Line:   line,
+		Reason: ErrMalformedRecord.Error(),
+		Error:  err.Error(),
+		Fields: fields,
+	}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		rejection.Field = fieldErr.Field
+		rejection.Reason = fieldErr.Err.Error()
+	}
+
+	r.Rejected = append(r.Rejected, rejection)
+}
+
+// RejectedCount number of rows skipped as malformed
+func (r *ImportReport) RejectedCount() int {
+	return len(r.Rejected)
+}
+
+// DominantReason returns the most frequent rejection reason with the field name and the number of rows
+func (r *ImportReport) DominantReason() (string, int) {
+	counts := make(map[string]int)
+
+	var (
+		dominant string
+		maxCount int
+	)
+
+	for _, rejection := range r.Rejected {
+		reason := rejection.Reason
+		if rejection.Field != "" {
+			reason = fmt.Sprintf("%s (%s)", rejection.Reason, rejection.Field)
+		}
+
+		counts[reason]++
+
+		if counts[reason] > maxCount {
+			dominant, maxCount = reason, counts[reason]
+		}
+	}
+
+	return dominant, maxCount
+}
+
+// CheckThreshold returns error if rejected rows exceed any of the limits
+func (r *ImportReport) CheckThreshold(threshold *RejectThreshold) error {
+	rejected := r.RejectedCount()
+	if rejected == 0 {
+		return nil
+	}
+
+	exceeded := threshold.Count != 0 && rejected > threshold.Count
+
+	if threshold.Percent != 0 && r.Total != 0 {
+		exceeded = exceeded || float64(rejected)*100 > threshold.Percent*float64(r.Total)
+	}
+
+	if !exceeded {
+		return nil
+	}
+
+	reason, count := r.DominantReason()
+
+	return fmt.Errorf("%w: %d of %d rows, mostly: %s: %d rows",
+		ErrTooManyRejected, rejected, r.Total, reason, count)
+}
+
+// WriteJSON writes the whole report as JSON document
+func (r *ImportReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes rejected rows, the raw fields follow the rejection columns, and time cells outside
+// working hours w/o line number, spec, name, start and end time and filial follow them
+func (r *ImportReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"line", "field", "reason", "error", "fields"}); err != nil {
+		return err
+	}
+
+	for _, rejection := range r.Rejected {
+		row := append([]string{
+			strconv.Itoa(rejection.Line),
+			rejection.Field,
+			rejection.Reason,
+			rejection.Error,
+		}, rejection.Fields...)
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	for _, cell := range r.OutOfHours {
+		row := []string{
+			"",
+			FieldStartTime,
+			ErrOutOfHours.Error(),
+			fmt.Sprintf("%v: %s", ErrOutOfHours, cell.Action),
+			cell.Spec,
+			cell.Name,
+			cell.StartTime.Format(TimeLayout),
+			cell.EndTime.Format(TimeLayout),
+			cell.Filial,
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
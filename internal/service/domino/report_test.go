@@ -0,0 +1,154 @@
+package domino_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+)
+
+const reportCSV = `"spec","name","cell","duration","free","room",
+"Уролог","Петров Г.А.","1.7.21 10:00:00","","free","12 кабинет",
+"Уролог",,"1.7.21 10:20:00","","free","12 кабинет",
+"Уролог","Петров Г.А.","31.6.21 10:40:00","","free","12 кабинет",
+"Уролог","Петров Г.А.","1.7.21 11:00:00","abc","free","12 кабинет",
+"Уролог","Петров Г.А.","1.5.21 11:00:00","20","free","12 кабинет",
+`
+
+func TestImportRecords_Report(t *testing.T) {
+	records, report, err := domino.ImportRecords(
+		[]byte(reportCSV),
+		&domino.Config{},
+		time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+		func(message string) {
+			t.Log(message)
+		},
+	)
+	if err != nil {
+		t.Fatalf("ImportRecords() error = %v", err)
+	}
+
+	if len(records) != 1 {
+		t.Errorf("ImportRecords() got %d records, want 1", len(records))
+	}
+
+	if report.Total != 5 || report.Imported != 1 || report.DroppedPast != 1 || report.RejectedCount() != 3 {
+		t.Errorf("ImportRecords() got report = %+v", report)
+	}
+
+	type rejection struct {
+		line   int
+		field  string
+		reason error
+	}
+
+	want := []rejection{
+		{line: 3, field: domino.FieldName, reason: domino.ErrMandatoryField},
+		{line: 4, field: domino.FieldStartTime, reason: domino.ErrBadTime},
+		{line: 5, field: domino.FieldDuration, reason: domino.ErrBadDuration},
+	}
+
+	got := make([]rejection, 0, len(report.Rejected))
+
+	for _, r := range report.Rejected {
+		got = append(got, rejection{line: r.Line, field: r.Field, reason: reasonError(r.Reason)})
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportRecords() got rejections = %v, want %v", got, want)
+	}
+
+	var buf bytes.Buffer
+
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	wantCSV := `line,field,reason,error,fields
+3,name,mandatory field is empty,mandatory field is empty: name,Уролог,,1.7.21 10:20:00,,free,12 кабинет,
+`
+
+	if gotCSV := buf.String(); !strings.HasPrefix(gotCSV, wantCSV) {
+		t.Errorf("WriteCSV() got = %s, want prefix %s", gotCSV, wantCSV)
+	}
+}
+
+func TestImportReport_WriteCSV_OutOfHours(t *testing.T) {
+	report := domino.NewImportReport()
+	report.OutOfHours = append(report.OutOfHours, &domino.OutOfHoursCell{
+		Spec:      "Уролог",
+		Name:      "Петров Г.А.",
+		Filial:    "north",
+		StartTime: time.Date(2021, 7, 1, 21, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2021, 7, 1, 21, 20, 0, 0, time.UTC),
+		Action:    "flag",
+	})
+
+	var buf bytes.Buffer
+
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	wantCSV := `line,field,reason,error,fields
+,cell,time cell outside working hours,time cell outside working hours: flag,Уролог,Петров Г.А.,` +
+		`1.7.21 21:00:00,1.7.21 21:20:00,north
+`
+
+	if gotCSV := buf.String(); gotCSV != wantCSV {
+		t.Errorf("WriteCSV() got = %s, want %s", gotCSV, wantCSV)
+	}
+}
+
+func reasonError(reason string) error {
+	for _, err := range []error{domino.ErrMandatoryField, domino.ErrBadTime, domino.ErrBadDuration} {
+		if err.Error() == reason {
+			return err
+		}
+	}
+
+	return domino.ErrMalformedRecord
+}
+
+func TestImportReport_CheckThreshold(t *testing.T) {
+	_, report, err := domino.ImportRecords(
+		[]byte(reportCSV),
+		&domino.Config{TimeLayouts: []string{"2.1.2006 15:04"}}, // every start time is malformed
+		time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+		func(message string) {},
+	)
+	if err != nil {
+		t.Fatalf("ImportRecords() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		threshold domino.RejectThreshold
+		wantErr   error
+	}{
+		{name: "no limits", threshold: domino.RejectThreshold{}, wantErr: nil},
+		{name: "count not exceeded", threshold: domino.RejectThreshold{Count: 5}, wantErr: nil},
+		{name: "count exceeded", threshold: domino.RejectThreshold{Count: 4}, wantErr: domino.ErrTooManyRejected},
+		{name: "percent not exceeded", threshold: domino.RejectThreshold{Percent: 100}, wantErr: nil},
+		{name: "percent exceeded", threshold: domino.RejectThreshold{Percent: 50}, wantErr: domino.ErrTooManyRejected},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := report.CheckThreshold(&tt.threshold)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckThreshold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), "mostly: failed to decode starting date field (cell): 4 rows") {
+				t.Errorf("CheckThreshold() error = %v, want dominant reason", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package domino
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// reshaping modes of doctor's time cells
+const (
+	ReshapeNone  = "none"
+	ReshapeSplit = "split" // cells longer than the slot are split into slots
+	ReshapeMerge = "merge" // contiguous cells of the same status and room are merged up to the slot
+)
+
+var (
+	ErrBadReshapeMode = errors.New("mode must be one of none, split, merge (reshape.mode option)")
+	ErrBadReshapeSlot = errors.New("slot must be positive for split mode (reshape.slot option)")
+)
+
+// ReshapeRule how doctor's time cells are reshaped before publishing
+type ReshapeRule struct {
+	Mode string        `yaml:"mode"`
+	Slot time.Duration `yaml:"slot"` // slot size; the merged cell size limit for merge mode, no limit if empty
+}
+
+func (r *ReshapeRule) check() error {
+	switch r.Mode {
+	case "", ReshapeNone, ReshapeMerge:
+	case ReshapeSplit:
+		if r.Slot <= 0 {
+			return ErrBadReshapeSlot
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrBadReshapeMode, r.Mode)
+	}
+
+	if r.Slot < 0 {
+		return ErrBadReshapeSlot
+	}
+
+	return nil
+}
+
+// Reshape reshaping rules, a specialty's rule overrides the global one
+type Reshape struct {
+	ReshapeRule `yaml:",inline"`
+
+	Specs map[string]ReshapeRule `yaml:"specs"`
+}
+
+func (r *Reshape) Check() error {
+	if err := r.ReshapeRule.check(); err != nil {
+		return err
+	}
+
+	for spec, rule := range r.Specs {
+		rule := rule
+		if err := rule.check(); err != nil {
+			return fmt.Errorf("%w: spec %s", err, spec)
+		}
+	}
+
+	return nil
+}
+
+// Rule returns the reshaping rule of the specialty
+func (r *Reshape) Rule(spec string) ReshapeRule {
+	if rule, ok := r.Specs[spec]; ok {
+		return rule
+	}
+
+	return r.ReshapeRule
+}
+
+// Reshape splits or merges time cells according to the rule, the cells must be ordered and w/o conflicts
+func (s *DoctorSchedule) Reshape(rule ReshapeRule) {
+	switch rule.Mode {
+	case ReshapeSplit:
+		s.Cells = splitCells(s.Cells, rule.Slot)
+	case ReshapeMerge:
+		s.Cells = mergeCells(s.Cells, rule.Slot)
+	}
+}
+
+// splitCells splits cells longer than the slot, the remainder shorter than the slot is published as a shorter cell
+func splitCells(cells TimeCells, slot time.Duration) TimeCells {
+	result := make(TimeCells, 0, len(cells))
+
+	for _, cell := range cells {
+		if cell.Duration <= slot {
+			result = append(result, cell)
+
+			continue
+		}
+
+		for offset := time.Duration(0); offset < cell.Duration; offset += slot {
+			part := *cell
+			part.StartTime = cell.StartTime.Add(offset)
+			part.Duration = slot
+
+			if offset+slot > cell.Duration {
+				part.Duration = cell.Duration - offset
+			}
+
+			result = append(result, &part)
+		}
+	}
+
+	return result
+}
+
+// mergeCells merges contiguous cells of the same status and room while the merged cell fits the slot
+func mergeCells(cells TimeCells, slot time.Duration) TimeCells {
+	result := make(TimeCells, 0, len(cells))
+
+	for _, cell := range cells {
+		n := len(result)
+		if n != 0 {
+			last := result[n-1]
+
+			if last.EndTime().Equal(cell.StartTime) && last.Free == cell.Free && last.Room == cell.Room &&
+				(slot == 0 || last.Duration+cell.Duration <= slot) {
+				last.Duration += cell.Duration
+
+				continue
+			}
+		}
+
+		merged := *cell
+		result = append(result, &merged)
+	}
+
+	return result
+}
@@ -0,0 +1,129 @@
+package domino_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+)
+
+func TestDoctorSchedule_Reshape(t *testing.T) {
+	otherRoom := cell(11, 50, 10*time.Minute, true)
+	otherRoom.Room = "14 кабинет"
+
+	cells := func() domino.TimeCells {
+		return domino.TimeCells{
+			cell(9, 0, 50*time.Minute, true),
+			cell(10, 0, 10*time.Minute, true),
+			cell(10, 10, 10*time.Minute, true),
+			cell(10, 20, 10*time.Minute, true),
+			cell(10, 30, 10*time.Minute, true),
+			cell(10, 40, 10*time.Minute, false),
+			cell(11, 40, 10*time.Minute, true),
+			otherRoom,
+		}
+	}
+
+	tests := []struct {
+		name string
+		rule domino.ReshapeRule
+		want domino.TimeCells
+	}{
+		{
+			name: "none",
+			rule: domino.ReshapeRule{},
+			want: cells(),
+		},
+		{
+			name: "split",
+			rule: domino.ReshapeRule{Mode: domino.ReshapeSplit, Slot: 20 * time.Minute},
+			want: append(domino.TimeCells{
+				cell(9, 0, 20*time.Minute, true),
+				cell(9, 20, 20*time.Minute, true),
+				cell(9, 40, 10*time.Minute, true),
+			}, cells()[1:]...),
+		},
+		{
+			name: "split exact",
+			rule: domino.ReshapeRule{Mode: domino.ReshapeSplit, Slot: 25 * time.Minute},
+			want: append(domino.TimeCells{
+				cell(9, 0, 25*time.Minute, true),
+				cell(9, 25, 25*time.Minute, true),
+			}, cells()[1:]...),
+		},
+		{
+			name: "merge",
+			rule: domino.ReshapeRule{Mode: domino.ReshapeMerge, Slot: 30 * time.Minute},
+			want: domino.TimeCells{
+				cell(9, 0, 50*time.Minute, true),
+				cell(10, 0, 30*time.Minute, true),
+				cell(10, 30, 10*time.Minute, true),
+				cell(10, 40, 10*time.Minute, false),
+				cell(11, 40, 10*time.Minute, true),
+				otherRoom,
+			},
+		},
+		{
+			name: "merge w/o limit",
+			rule: domino.ReshapeRule{Mode: domino.ReshapeMerge},
+			want: domino.TimeCells{
+				cell(9, 0, 50*time.Minute, true),
+				cell(10, 0, 40*time.Minute, true),
+				cell(10, 40, 10*time.Minute, false),
+				cell(11, 40, 10*time.Minute, true),
+				otherRoom,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &domino.DoctorSchedule{Cells: cells()}
+			schedule.Reshape(tt.rule)
+
+			if !reflect.DeepEqual(schedule.Cells, tt.want) {
+				for i, c := range schedule.Cells {
+					t.Errorf("Got item %d: %v", i, c)
+				}
+
+				t.Errorf("Reshape() got %d cells, want %d", len(schedule.Cells), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestReshape_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		reshape domino.Reshape
+		wantErr error
+	}{
+		{name: "empty", reshape: domino.Reshape{}, wantErr: nil},
+		{
+			name:    "bad mode",
+			reshape: domino.Reshape{ReshapeRule: domino.ReshapeRule{Mode: "join"}},
+			wantErr: domino.ErrBadReshapeMode,
+		},
+		{
+			name: "split w/o slot",
+			reshape: domino.Reshape{Specs: map[string]domino.ReshapeRule{
+				"Терапевт": {Mode: domino.ReshapeSplit},
+			}},
+			wantErr: domino.ErrBadReshapeSlot,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.reshape.Check(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package domino
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// slot statuses, a raw status code of the Domino export is mapped to one of them
+const (
+	StatusFree = "free"
+	StatusBusy = "busy"
+	StatusDrop = "drop" // slot is not published at all
+)
+
+// policies of unknown status codes
+const (
+	UnknownStatusDrop  = "drop"
+	UnknownStatusBusy  = "busy"
+	UnknownStatusError = "error" // the record is rejected and goes to the validation report
+
+	DefaultUnknownStatus = UnknownStatusBusy
+)
+
+var (
+	ErrBadStatus        = errors.New("status must be one of free, busy, drop (statuses.codes option)")
+	ErrBadUnknownStatus = errors.New("policy must be one of drop, busy, error (statuses.unknown option)")
+	ErrUnknownStatus    = errors.New("unknown status code")
+	ErrDroppedStatus    = errors.New("record dropped by status code")
+)
+
+// Statuses maps raw status codes of the Domino export to slot statuses,
+// if no codes are configured the "busy" code means busy slot and anything else means free one
+type Statuses struct {
+	Codes   map[string]string `yaml:"codes"`
+	Unknown string            `yaml:"unknown"`
+
+	codes map[string]string
+}
+
+func normalizeStatusCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+func (s *Statuses) Check() error {
+	if s.Unknown == "" {
+		s.Unknown = DefaultUnknownStatus
+	}
+
+	switch s.Unknown {
+	case UnknownStatusDrop, UnknownStatusBusy, UnknownStatusError:
+	default:
+		return fmt.Errorf("%w: %s", ErrBadUnknownStatus, s.Unknown)
+	}
+
+	s.codes = make(map[string]string, len(s.Codes))
+
+	for code, status := range s.Codes {
+		switch status {
+		case StatusFree, StatusBusy, StatusDrop:
+		default:
+			return fmt.Errorf("%w: %s: %s", ErrBadStatus, code, status)
+		}
+
+		s.codes[normalizeStatusCode(code)] = status
+	}
+
+	return nil
+}
+
+// isKnown reports whether the code is listed in the mapping
+func (s *Statuses) isKnown(code string) bool {
+	if len(s.codes) == 0 {
+		return true
+	}
+
+	_, ok := s.codes[normalizeStatusCode(code)]
+
+	return ok
+}
+
+// resolve returns the slot status of the raw code, error if the code is unknown and the policy is "error"
+func (s *Statuses) resolve(code string) (string, error) {
+	if len(s.codes) == 0 {
+		if code == BusyCode {
+			return StatusBusy, nil
+		}
+
+		return StatusFree, nil // empty field equals free time
+	}
+
+	if status, ok := s.codes[normalizeStatusCode(code)]; ok {
+		return status, nil
+	}
+
+	switch s.Unknown {
+	case UnknownStatusDrop:
+		return StatusDrop, nil
+	case UnknownStatusError:
+		return "", ErrUnknownStatus
+	default:
+		return StatusBusy, nil
+	}
+}
@@ -0,0 +1,142 @@
+package domino_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+)
+
+const statusCSV = `"spec","name","cell","duration","free","room",
+"Уролог","Петров Г.А.","1.7.21 10:00:00","20","","12 кабинет",
+"Уролог","Петров Г.А.","1.7.21 10:20:00","20","busy","12 кабинет",
+"Уролог","Петров Г.А.","1.7.21 10:40:00","20","Reserved","12 кабинет",
+"Уролог","Петров Г.А.","1.7.21 11:00:00","20","vacation","12 кабинет",
+"Уролог","Петров Г.А.","1.7.21 11:20:00","20","phone only","12 кабинет",
+"Уролог","Петров Г.А.","1.7.21 11:40:00","20","Phone Only","12 кабинет",
+"Уролог","Петров Г.А.","30.6.21 11:40:00","20","phone only","12 кабинет",
+`
+
+func TestImportRecords_Statuses(t *testing.T) {
+	codes := map[string]string{
+		"":         domino.StatusFree,
+		"free":     domino.StatusFree,
+		"busy":     domino.StatusBusy,
+		"reserved": domino.StatusBusy,
+		"vacation": domino.StatusDrop,
+	}
+
+	tests := []struct {
+		name          string
+		statuses      domino.Statuses
+		wantFree      []bool
+		wantDropped   int
+		wantRejected  int
+		wantUnknown   map[string]int
+		wantRejectErr error
+	}{
+		{
+			name:        "legacy",
+			statuses:    domino.Statuses{},
+			wantFree:    []bool{true, false, true, true, true, true},
+			wantUnknown: map[string]int{},
+		},
+		{
+			name:        "unknown busy",
+			statuses:    domino.Statuses{Codes: codes, Unknown: domino.UnknownStatusBusy},
+			wantFree:    []bool{true, false, false, false, false},
+			wantDropped: 1,
+			wantUnknown: map[string]int{"phone only": 2},
+		},
+		{
+			name:        "unknown drop",
+			statuses:    domino.Statuses{Codes: codes, Unknown: domino.UnknownStatusDrop},
+			wantFree:    []bool{true, false, false},
+			wantDropped: 3,
+			wantUnknown: map[string]int{"phone only": 2},
+		},
+		{
+			name:          "unknown error",
+			statuses:      domino.Statuses{Codes: codes, Unknown: domino.UnknownStatusError},
+			wantFree:      []bool{true, false, false},
+			wantDropped:   1,
+			wantRejected:  2,
+			wantUnknown:   map[string]int{"phone only": 2},
+			wantRejectErr: domino.ErrUnknownStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			config := &domino.Config{Statuses: tt.statuses}
+			if err := config.Statuses.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			records, report, err := domino.ImportRecords(
+				[]byte(statusCSV),
+				config,
+				time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+				func(message string) {
+					t.Log(message)
+				},
+			)
+			if err != nil {
+				t.Fatalf("ImportRecords() error = %v", err)
+			}
+
+			gotFree := make([]bool, 0, len(records))
+			for _, r := range records.Cleaned(domino.DefaultDurationRule) {
+				gotFree = append(gotFree, r.Free)
+			}
+
+			if !reflect.DeepEqual(gotFree, tt.wantFree) {
+				t.Errorf("ImportRecords() got free = %v, want %v", gotFree, tt.wantFree)
+			}
+
+			if report.DroppedStatus != tt.wantDropped || report.RejectedCount() != tt.wantRejected {
+				t.Errorf("ImportRecords() got report = %+v", report)
+			}
+
+			if len(report.DoctorDays) != 2 { // dropped and expired rows included
+				t.Errorf("ImportRecords() got doctor days = %v, want 2", report.DoctorDays)
+			}
+
+			if !reflect.DeepEqual(report.UnknownStatuses, tt.wantUnknown) {
+				t.Errorf("ImportRecords() got unknown = %v, want %v", report.UnknownStatuses, tt.wantUnknown)
+			}
+
+			for _, r := range report.Rejected {
+				if r.Field != domino.FieldFree || r.Reason != tt.wantRejectErr.Error() {
+					t.Errorf("ImportRecords() got rejection = %+v", r)
+				}
+			}
+		})
+	}
+}
+
+func TestStatuses_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses domino.Statuses
+		wantErr  error
+	}{
+		{name: "empty", statuses: domino.Statuses{}, wantErr: nil},
+		{name: "bad status", statuses: domino.Statuses{Codes: map[string]string{"x": "maybe"}}, wantErr: domino.ErrBadStatus},
+		{name: "bad policy", statuses: domino.Statuses{Unknown: "free"}, wantErr: domino.ErrBadUnknownStatus},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.statuses.Check(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
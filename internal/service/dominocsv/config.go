@@ -0,0 +1,62 @@
+package dominocsv
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+var (
+	ErrBadDelimiter = errors.New("delimiter must be a single character (csv.delimiter option)")
+	ErrBadComment   = errors.New("comment must be a single character (csv.comment option)")
+)
+
+// Config CSV dialect of the Domino export, zero value equals encoding/csv defaults
+type Config struct {
+	Delimiter        string `yaml:"delimiter"`
+	LazyQuotes       bool   `yaml:"lazy_quotes"`
+	TrimLeadingSpace bool   `yaml:"trim_leading_space"`
+	Comment          string `yaml:"comment"`
+
+	delimiter rune
+	comment   rune
+}
+
+func (c *Config) Check() error {
+	var err error
+
+	if c.Delimiter != "" {
+		c.delimiter, err = singleRune(c.Delimiter)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrBadDelimiter, err)
+		}
+	}
+
+	if c.Comment != "" {
+		c.comment, err = singleRune(c.Comment)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrBadComment, err)
+		}
+
+		if c.comment == c.delimiter || (c.delimiter == 0 && c.comment == ',') {
+			return fmt.Errorf("%w: equals to delimiter", ErrBadComment)
+		}
+	}
+
+	return nil
+}
+
+var errBadRune = errors.New("invalid character")
+
+func singleRune(s string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) {
+		return 0, fmt.Errorf("%w: %q", errBadRune, s)
+	}
+
+	if r == utf8.RuneError || r == '\r' || r == '\n' || r == '"' {
+		return 0, fmt.Errorf("%w: %q", errBadRune, s)
+	}
+
+	return r, nil
+}
@@ -2,52 +2,40 @@ package dominocsv
 
 import (
 	"encoding/csv"
-	"fmt"
+	"html"
 	"io"
-	"regexp"
-	"strconv"
+	"strings"
 )
 
-const (
-	prefix    = `&\#`
-	prefixLen = 2
-
-	suffix    = `;`
-	suffixLen = 1
-
-	minUnquotedLen = 4
+// nonBreakingSpaces is replaced by a regular space, so names and rooms compare as typed in HIS
+var nonBreakingSpaces = strings.NewReplacer(
+	"\u00a0", " ", // NO-BREAK SPACE, &nbsp;
+	"\u2007", " ", // FIGURE SPACE
+	"\u202f", " ", // NARROW NO-BREAK SPACE
 )
 
-var re *regexp.Regexp
-
-func init() {
-	re = regexp.MustCompile(fmt.Sprintf(`%s\d+%s`, prefix, suffix))
-}
-
-// DecodeValue - unquotes symbols in a string value
+// DecodeValue - unquotes HTML5 character references (decimal, hex and named) in a string value
 func DecodeValue(text string) string {
-	return re.ReplaceAllStringFunc(text, func(s string) string {
-		n := len(s)
-		if n < minUnquotedLen {
-			return s
-		}
-
-		i, err := strconv.Atoi(s[prefixLen : n-suffixLen])
-		if err != nil {
-			return s
-		}
-
-		return string(rune(i))
-	})
+	return nonBreakingSpaces.Replace(html.UnescapeString(text))
 }
 
 type Reader struct {
 	reader *csv.Reader
 }
 
-func NewReader(in io.Reader) (*Reader, error) {
+// NewReader creates a reader for the CSV dialect described by config (see Config.Check)
+func NewReader(in io.Reader, config *Config) (*Reader, error) {
+	reader := csv.NewReader(in)
+	reader.LazyQuotes = config.LazyQuotes
+	reader.TrimLeadingSpace = config.TrimLeadingSpace
+	reader.Comment = config.comment
+
+	if config.delimiter != 0 {
+		reader.Comma = config.delimiter
+	}
+
 	r := &Reader{
-		reader: csv.NewReader(in),
+		reader: reader,
 	}
 
 	return r, nil
@@ -67,3 +55,10 @@ func (r *Reader) Read() ([]string, error) {
 
 	return record, nil
 }
+
+// Line returns the line number where the most recently read record starts
+func (r *Reader) Line() int {
+	line, _ := r.reader.FieldPos(0)
+
+	return line
+}
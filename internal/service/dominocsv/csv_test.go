@@ -4,9 +4,12 @@ import (
 	"bytes"
 	_ "embed"
 	"errors"
+	"fmt"
 	"io"
 	"reflect"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"prodoctorov/internal/service/dominocsv"
 )
@@ -49,6 +52,34 @@ func TestDecodeValue(t *testing.T) {
 			},
 			want: "31.7.21",
 		},
+		{
+			name: "hex references",
+			args: args{
+				text: "&#x41F;&#x435;&#x442;&#x440;&#x43E;&#x432; &#X413;.&#x411;.",
+			},
+			want: "Петров Г.Б.",
+		},
+		{
+			name: "named references",
+			args: args{
+				text: "&quot;&#1050;&#1083;&#1080;&#1085;&#1080;&#1082;&#1072;&quot; &amp; &lt;11&gt;",
+			},
+			want: `"Клиника" & <11>`,
+		},
+		{
+			name: "non-breaking spaces",
+			args: args{
+				text: "12&nbsp;&#1082;&#1072;&#1073;.&#160;2\u00a0&#x202F;",
+			},
+			want: "12 каб. 2  ",
+		},
+		{
+			name: "not a reference",
+			args: args{
+				text: "R&D &#; &#xZZ; &unknown;",
+			},
+			want: "R&D &#; &#xZZ; &unknown;",
+		},
 	}
 
 	for _, tt := range tests {
@@ -63,13 +94,76 @@ func TestDecodeValue(t *testing.T) {
 	}
 }
 
+// encodeValue emulates Domino export, every rune is a decimal or a hex character reference
+func encodeValue(text string, hex bool) string {
+	var b strings.Builder
+
+	for _, r := range text {
+		if hex {
+			fmt.Fprintf(&b, "&#x%X;", r)
+		} else {
+			fmt.Fprintf(&b, "&#%d;", r)
+		}
+	}
+
+	return b.String()
+}
+
+// isRoundTrip reports whether HTML5 decodes a numeric reference of every rune back to itself
+func isRoundTrip(text string) bool {
+	for _, r := range text {
+		if r == 0 || (r >= 0x80 && r <= 0x9f) || r == utf8.RuneError {
+			return false // replaced by HTML5 rules
+		}
+	}
+
+	return utf8.ValidString(text)
+}
+
+var plainSpaces = strings.NewReplacer("\u00a0", " ", "\u2007", " ", "\u202f", " ")
+
+func FuzzDecodeValue(f *testing.F) {
+	for _, seed := range []string{
+		"Иванов И.И.",
+		"4 кабинет",
+		"31.7.21",
+		"&amp;&nbsp;&#x41F;&#1083;",
+		"R&D",
+		"\u00a0",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		got := dominocsv.DecodeValue(text)
+
+		if strings.ContainsAny(got, "\u00a0\u2007\u202f") {
+			t.Errorf("DecodeValue(%q) = %q has non-breaking space", text, got)
+		}
+
+		if !strings.Contains(text, "&") && got != plainSpaces.Replace(text) {
+			t.Errorf("DecodeValue(%q) = %q, plain text changed", text, got)
+		}
+
+		if !isRoundTrip(text) || strings.ContainsAny(text, "\u00a0\u2007\u202f") {
+			return
+		}
+
+		for _, hex := range []bool{false, true} {
+			if got := dominocsv.DecodeValue(encodeValue(text, hex)); got != text {
+				t.Errorf("DecodeValue(encodeValue(%q, %v)) = %q", text, hex, got)
+			}
+		}
+	})
+}
+
 var (
 	//go:embed testdata.csv
 	testCSV []byte
 )
 
 func TestReader_Read(t *testing.T) {
-	reader, err := dominocsv.NewReader(bytes.NewReader(testCSV))
+	reader, err := dominocsv.NewReader(bytes.NewReader(testCSV), &dominocsv.Config{})
 	if err != nil {
 		t.Fatalf("NewReader() error = %v", err)
 	}
@@ -183,3 +277,100 @@ func TestReader_Read(t *testing.T) {
 		})
 	}
 }
+
+func TestReader_Dialect(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  dominocsv.Config
+		data    string
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name:   "semicolon",
+			config: dominocsv.Config{Delimiter: ";"},
+			data:   "\"&#1051;&#1054;&#1056;\";\"1.7.21 10:00:00\";\"30\"\n",
+			want:   [][]string{{"ЛОР", "1.7.21 10:00:00", "30"}},
+		},
+		{
+			name:    "bare quote",
+			config:  dominocsv.Config{},
+			data:    "ЛОР \"11\",\"30\"\n",
+			wantErr: true,
+		},
+		{
+			name:   "lazy quotes",
+			config: dominocsv.Config{LazyQuotes: true},
+			data:   "ЛОР \"11\",\"30\"\n",
+			want:   [][]string{{"ЛОР \"11\"", "30"}},
+		},
+		{
+			name:   "trim leading space and comments",
+			config: dominocsv.Config{TrimLeadingSpace: true, Comment: "#"},
+			data:   "# header comment\n\"ЛОР\",  \"30\"\n",
+			want:   [][]string{{"ЛОР", "30"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			reader, err := dominocsv.NewReader(strings.NewReader(tt.data), &tt.config)
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+
+			got := make([][]string, 0)
+
+			for {
+				record, err := reader.Read()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Read() error = %v, wantErr %v", err, tt.wantErr)
+				}
+
+				if err != nil {
+					return
+				}
+
+				got = append(got, record)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Read() got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  dominocsv.Config
+		wantErr error
+	}{
+		{name: "defaults", config: dominocsv.Config{}, wantErr: nil},
+		{name: "tab", config: dominocsv.Config{Delimiter: "\t"}, wantErr: nil},
+		{name: "long delimiter", config: dominocsv.Config{Delimiter: ";;"}, wantErr: dominocsv.ErrBadDelimiter},
+		{name: "newline delimiter", config: dominocsv.Config{Delimiter: "\n"}, wantErr: dominocsv.ErrBadDelimiter},
+		{name: "comment equals comma", config: dominocsv.Config{Comment: ","}, wantErr: dominocsv.ErrBadComment},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Check(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package filials
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoDefault     = errors.New("default filial must be set if no rules configured (filials.default option)")
+	ErrUnknownFilial = errors.New("filial is not in the filial list (filials.ids option)")
+	ErrDuplicateID   = errors.New("duplicate filial identifier (filials.ids option)")
+	ErrNoCondition   = errors.New("rule has no room_prefix, code nor doctor (filials.rules option)")
+)
+
+// Rule assigns records matched by any of the conditions to the filial
+type Rule struct {
+	Filial     string `yaml:"filial"`
+	RoomPrefix string `yaml:"room_prefix"`
+	Code       string `yaml:"code"`   // value of the filial column, see domino.Config.FilialColumn
+	Doctor     string `yaml:"doctor"` // doctor's name or external identifier
+}
+
+// Config filial assignment, if no filial identifiers are configured the single filial is uploaded;
+// records not matched by rules go to the default filial, they are skipped if it is not set
+type Config struct {
+	IDs     []string `yaml:"ids"`
+	Default string   `yaml:"default"`
+	Rules   []*Rule  `yaml:"rules"`
+
+	ids map[string]struct{}
+}
+
+func (c *Config) IsEnabled() bool {
+	return len(c.IDs) != 0
+}
+
+func (c *Config) Check() error {
+	c.ids = make(map[string]struct{}, len(c.IDs))
+
+	for _, id := range c.IDs {
+		if _, ok := c.ids[id]; ok || id == "" {
+			return fmt.Errorf("%w: %q", ErrDuplicateID, id)
+		}
+
+		c.ids[id] = struct{}{}
+	}
+
+	if !c.IsEnabled() {
+		return nil
+	}
+
+	if c.Default != "" && !c.isKnown(c.Default) {
+		return fmt.Errorf("%w: %s", ErrUnknownFilial, c.Default)
+	}
+
+	if c.Default == "" && len(c.Rules) == 0 {
+		return ErrNoDefault
+	}
+
+	for i, rule := range c.Rules {
+		if !c.isKnown(rule.Filial) {
+			return fmt.Errorf("%w: %q, rule %d", ErrUnknownFilial, rule.Filial, i)
+		}
+
+		if rule.RoomPrefix == "" && rule.Code == "" && rule.Doctor == "" {
+			return fmt.Errorf("%w: rule %d", ErrNoCondition, i)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) isKnown(id string) bool {
+	_, ok := c.ids[id]
+
+	return ok
+}
@@ -0,0 +1,65 @@
+package filials
+
+import (
+	"sort"
+	"strings"
+
+	"prodoctorov/internal/service/domino"
+)
+
+// Match reports whether any condition of the rule matches the record
+func (r *Rule) Match(record *domino.Record) bool {
+	switch {
+	case r.RoomPrefix != "" && strings.HasPrefix(record.Room, r.RoomPrefix):
+		return true
+	case r.Code != "" && record.FilialCode == r.Code:
+		return true
+	case r.Doctor != "" && (record.Name == r.Doctor || record.DoctorID == r.Doctor):
+		return true
+	default:
+		return false
+	}
+}
+
+// Filial returns the filial of the record by the first rule matched or the default one
+func (c *Config) Filial(record *domino.Record) (string, bool) {
+	for _, rule := range c.Rules {
+		if rule.Match(record) {
+			return rule.Filial, true
+		}
+	}
+
+	return c.Default, c.Default != ""
+}
+
+// Apply assigns filials to the records, returns the records left and sorted schedule identifiers
+// of the records skipped w/o filial
+func Apply(config *Config, records domino.Records) (domino.Records, []string) {
+	if !config.IsEnabled() {
+		return records, []string{}
+	}
+
+	skipped := make(map[string]struct{})
+	result := make(domino.Records, 0, len(records))
+
+	for _, r := range records {
+		filial, ok := config.Filial(r)
+		if !ok {
+			skipped[r.ID()] = struct{}{}
+
+			continue
+		}
+
+		r.Filial = filial
+		result = append(result, r)
+	}
+
+	ids := make([]string, 0, len(skipped))
+	for id := range skipped {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return result, ids
+}
@@ -0,0 +1,130 @@
+package filials_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/filials"
+)
+
+func TestApply(t *testing.T) {
+	rules := []*filials.Rule{
+		{Filial: "north", RoomPrefix: "С-"},
+		{Filial: "south", Code: "ЮГ"},
+		{Filial: "south", Doctor: "Сидоров С.С."},
+	}
+
+	tests := []struct {
+		name        string
+		config      filials.Config
+		record      *domino.Record
+		want        string
+		wantSkipped []string
+	}{
+		{
+			name:        "disabled",
+			config:      filials.Config{},
+			record:      &domino.Record{Spec: "Терапевт", Name: "Иванов И.И.", Room: "С-12"},
+			want:        "",
+			wantSkipped: []string{},
+		},
+		{
+			name:        "room prefix",
+			config:      filials.Config{IDs: []string{"main", "north", "south"}, Default: "main", Rules: rules},
+			record:      &domino.Record{Spec: "Терапевт", Name: "Иванов И.И.", Room: "С-12"},
+			want:        "north",
+			wantSkipped: []string{},
+		},
+		{
+			name:        "filial code",
+			config:      filials.Config{IDs: []string{"main", "north", "south"}, Default: "main", Rules: rules},
+			record:      &domino.Record{Spec: "Терапевт", Name: "Петров П.П.", Room: "Процедурная 1", FilialCode: "ЮГ"},
+			want:        "south",
+			wantSkipped: []string{},
+		},
+		{
+			name:        "doctor",
+			config:      filials.Config{IDs: []string{"main", "north", "south"}, Default: "main", Rules: rules},
+			record:      &domino.Record{Spec: "Массажист", Name: "Сидоров С.С.", Room: "3 кабинет"},
+			want:        "south",
+			wantSkipped: []string{},
+		},
+		{
+			name:        "default",
+			config:      filials.Config{IDs: []string{"main", "north", "south"}, Default: "main", Rules: rules},
+			record:      &domino.Record{Spec: "Уролог", Name: "Козлов К.К.", Room: "14 кабинет"},
+			want:        "main",
+			wantSkipped: []string{},
+		},
+		{
+			name:        "w/o default",
+			config:      filials.Config{IDs: []string{"north", "south"}, Rules: rules},
+			record:      &domino.Record{Spec: "Уролог", Name: "Козлов К.К.", Room: "14 кабинет"},
+			want:        "",
+			wantSkipped: []string{"Уролог/Козлов К.К."},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			got, skipped := filials.Apply(&tt.config, domino.Records{tt.record})
+
+			if len(got) != 1-len(tt.wantSkipped) {
+				t.Fatalf("Apply() got %d records, skipped %v", len(got), skipped)
+			}
+
+			if len(got) == 1 && got[0].Filial != tt.want {
+				t.Errorf("Apply() got = %v, want %v", got[0].Filial, tt.want)
+			}
+
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("Apply() got skipped = %v, want %v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestConfig_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  filials.Config
+		wantErr error
+	}{
+		{name: "disabled", config: filials.Config{}, wantErr: nil},
+		{name: "duplicate", config: filials.Config{IDs: []string{"main", "main"}}, wantErr: filials.ErrDuplicateID},
+		{name: "no default", config: filials.Config{IDs: []string{"main"}}, wantErr: filials.ErrNoDefault},
+		{
+			name:    "unknown default",
+			config:  filials.Config{IDs: []string{"main"}, Default: "north"},
+			wantErr: filials.ErrUnknownFilial,
+		},
+		{
+			name:    "unknown rule filial",
+			config:  filials.Config{IDs: []string{"main"}, Rules: []*filials.Rule{{Filial: "north", Code: "С"}}},
+			wantErr: filials.ErrUnknownFilial,
+		},
+		{
+			name:    "no condition",
+			config:  filials.Config{IDs: []string{"main"}, Rules: []*filials.Rule{{Filial: "main"}}},
+			wantErr: filials.ErrNoCondition,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Check(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"prodoctorov/internal/service/names"
+)
+
+var (
+	ErrEmptyRule = errors.New("rule has no conditions")
+)
+
+// Rule matches a record if all conditions set match it; conditions are exact values or regular expressions
+type Rule struct {
+	Name string `yaml:"name"` // optional rule name for logs
+
+	Spec        string `yaml:"spec"`
+	SpecRegex   string `yaml:"spec_regex"`
+	Doctor      string `yaml:"doctor"` // doctor's name or external identifier
+	DoctorRegex string `yaml:"doctor_regex"`
+	Room        string `yaml:"room"`
+	RoomRegex   string `yaml:"room_regex"`
+
+	specRegex   *regexp.Regexp
+	doctor      string // normalized name, see names.Normalize
+	doctorRegex *regexp.Regexp
+	roomRegex   *regexp.Regexp
+}
+
+func compile(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	return regexp.Compile(expr)
+}
+
+func (r *Rule) check(label string) error {
+	var err error
+
+	if r.specRegex, err = compile(r.SpecRegex); err != nil {
+		return err
+	}
+
+	if r.doctorRegex, err = compile(r.DoctorRegex); err != nil {
+		return err
+	}
+
+	r.doctor = names.Normalize(r.Doctor)
+
+	if r.roomRegex, err = compile(r.RoomRegex); err != nil {
+		return err
+	}
+
+	if r.Spec == "" && r.specRegex == nil && r.Doctor == "" && r.doctorRegex == nil && r.Room == "" && r.roomRegex == nil {
+		return ErrEmptyRule
+	}
+
+	if r.Name == "" {
+		r.Name = label
+	}
+
+	return nil
+}
+
+// String describes conditions of the rule
+func (r *Rule) String() string {
+	conditions := make([]string, 0)
+
+	for _, c := range []struct{ key, value string }{
+		{"spec", r.Spec}, {"spec_regex", r.SpecRegex},
+		{"doctor", r.Doctor}, {"doctor_regex", r.DoctorRegex},
+		{"room", r.Room}, {"room_regex", r.RoomRegex},
+	} {
+		if c.value != "" {
+			conditions = append(conditions, fmt.Sprintf("%s=%q", c.key, c.value))
+		}
+	}
+
+	return strings.Join(conditions, " ")
+}
+
+// Config of the records filter; if include rules are set only records matched by any of them are kept,
+// records matched by any exclude rule are dropped
+type Config struct {
+	Include []*Rule `yaml:"include"`
+	Exclude []*Rule `yaml:"exclude"`
+}
+
+func (c *Config) Check() error {
+	for i, rule := range c.Include {
+		if err := rule.check(fmt.Sprintf("include[%d]", i)); err != nil {
+			return fmt.Errorf("bad include rule %d: %w", i, err)
+		}
+	}
+
+	for i, rule := range c.Exclude {
+		if err := rule.check(fmt.Sprintf("exclude[%d]", i)); err != nil {
+			return fmt.Errorf("bad exclude rule %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
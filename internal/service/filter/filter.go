@@ -0,0 +1,92 @@
+package filter
+
+import (
+	"regexp"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/names"
+)
+
+func matchValue(exact string, re *regexp.Regexp, values ...string) bool {
+	if exact == "" && re == nil {
+		return true
+	}
+
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+
+		if (exact == "" || value == exact) && (re == nil || re.MatchString(value)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match reports whether all conditions of the rule match the record
+func (r *Rule) Match(record *domino.Record) bool {
+	return matchValue(r.Spec, r.specRegex, record.Spec) &&
+		r.matchDoctor(record) &&
+		matchValue(r.Room, r.roomRegex, record.Room)
+}
+
+// matchDoctor matches the name regardless of spelling variants or the external identifier exactly,
+// the regex is matched against raw values
+func (r *Rule) matchDoctor(record *domino.Record) bool {
+	if r.Doctor != "" && names.Normalize(record.Name) != r.doctor && record.DoctorID != r.Doctor {
+		return false
+	}
+
+	return r.doctorRegex == nil || matchValue("", r.doctorRegex, record.Name, record.DoctorID)
+}
+
+// Hits number of records matched by every rule, by rule name
+type Hits map[string]int
+
+func firstMatch(rules []*Rule, record *domino.Record) *Rule {
+	for _, rule := range rules {
+		if rule.Match(record) {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// Apply returns records passed the filter and hit counts of the rules,
+// a record is counted by the first include and the first exclude rule matched
+func Apply(config *Config, records domino.Records) (domino.Records, Hits) {
+	hits := make(Hits)
+	result := make(domino.Records, 0, len(records))
+
+	for _, rule := range config.Include {
+		hits[rule.Name] = 0
+	}
+
+	for _, rule := range config.Exclude {
+		hits[rule.Name] = 0
+	}
+
+	for _, r := range records {
+		if len(config.Include) != 0 {
+			rule := firstMatch(config.Include, r)
+			if rule == nil {
+				continue
+			}
+
+			hits[rule.Name]++
+		}
+
+		if rule := firstMatch(config.Exclude, r); rule != nil {
+			hits[rule.Name]++
+
+			continue
+		}
+
+		result = append(result, r)
+	}
+
+	return result, hits
+}
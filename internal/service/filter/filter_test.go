@@ -0,0 +1,205 @@
+package filter_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/filter"
+)
+
+func TestRule_Match(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   filter.Rule
+		record *domino.Record
+		want   bool
+	}{
+		{
+			name:   "spec",
+			rule:   filter.Rule{Spec: "Массажист"},
+			record: &domino.Record{Spec: "Массажист", Name: "Сидоров С.С.", Room: "3 кабинет"},
+			want:   true,
+		},
+		{
+			name:   "spec regex",
+			rule:   filter.Rule{SpecRegex: "^Терап"},
+			record: &domino.Record{Spec: "Терапевт участковый", Name: "Иванов И.И.", Room: "12 кабинет"},
+			want:   true,
+		},
+		{
+			name:   "doctor by name",
+			rule:   filter.Rule{Doctor: "Иванов И.И."},
+			record: &domino.Record{Spec: "Терапевт", Name: "Иванов И.И.", Room: "12 кабинет"},
+			want:   true,
+		},
+		{
+			name:   "doctor spelling",
+			rule:   filter.Rule{Doctor: "иванов  и. и."},
+			record: &domino.Record{Spec: "Терапевт", Name: "Иванов И.И.", Room: "12 кабинет"},
+			want:   true,
+		},
+		{
+			name:   "doctor yo",
+			rule:   filter.Rule{Doctor: "Семенов С.С."},
+			record: &domino.Record{Spec: "Терапевт", Name: "Семёнов С. С.", Room: "12 кабинет"},
+			want:   true,
+		},
+		{
+			name:   "doctor regex on raw name",
+			rule:   filter.Rule{DoctorRegex: "^Семен"},
+			record: &domino.Record{Spec: "Терапевт", Name: "Семёнов С.С.", Room: "12 кабинет"},
+			want:   false,
+		},
+		{
+			name:   "doctor by id",
+			rule:   filter.Rule{Doctor: "1001"},
+			record: &domino.Record{Spec: "Терапевт", Name: "Иванов И.И.", Room: "12 кабинет", DoctorID: "1001"},
+			want:   true,
+		},
+		{
+			name:   "other doctor",
+			rule:   filter.Rule{Doctor: "1001"},
+			record: &domino.Record{Spec: "Терапевт", Name: "Петров П.П.", Room: "12 кабинет", DoctorID: "1002"},
+			want:   false,
+		},
+		{
+			name:   "doctor regex",
+			rule:   filter.Rule{DoctorRegex: "^(Сидоров|Козлов) "},
+			record: &domino.Record{Spec: "Уролог", Name: "Козлов К.К.", Room: "14 кабинет"},
+			want:   true,
+		},
+		{
+			name:   "room regex",
+			rule:   filter.Rule{RoomRegex: "^Процедурная"},
+			record: &domino.Record{Spec: "Терапевт", Name: "Петров П.П.", Room: "Процедурная 1"},
+			want:   true,
+		},
+		{
+			name:   "all conditions",
+			rule:   filter.Rule{Spec: "Терапевт", Doctor: "Петров П.П."},
+			record: &domino.Record{Spec: "Уролог", Name: "Петров П.П.", Room: "14 кабинет"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			config := filter.Config{Include: []*filter.Rule{&tt.rule}}
+			if err := config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			if got := tt.rule.Match(tt.record); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	records := func() domino.Records {
+		return domino.Records{
+			{Spec: "Терапевт", Name: "Иванов И.И.", Room: "12 кабинет", DoctorID: "1001"},
+			{Spec: "Терапевт", Name: "Петров П.П.", Room: "Процедурная 1"},
+			{Spec: "Массажист", Name: "Сидоров С.С.", Room: "3 кабинет"},
+			{Spec: "Уролог", Name: "Козлов К.К.", Room: "14 кабинет"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		config   filter.Config
+		want     []string
+		wantHits filter.Hits
+	}{
+		{
+			name:     "disabled",
+			config:   filter.Config{},
+			want:     []string{"Иванов И.И.", "Петров П.П.", "Сидоров С.С.", "Козлов К.К."},
+			wantHits: filter.Hits{},
+		},
+		{
+			name: "exclude",
+			config: filter.Config{
+				Exclude: []*filter.Rule{
+					{Name: "massage", Spec: "Массажист"},
+					{RoomRegex: "^Процедурная"},
+					{Doctor: "1001"},
+				},
+			},
+			want:     []string{"Козлов К.К."},
+			wantHits: filter.Hits{"massage": 1, "exclude[1]": 1, "exclude[2]": 1},
+		},
+		{
+			name: "include",
+			config: filter.Config{
+				Include: []*filter.Rule{{SpecRegex: "^Терап"}},
+				Exclude: []*filter.Rule{{Spec: "Терапевт", Doctor: "Петров П.П."}},
+			},
+			want:     []string{"Иванов И.И."},
+			wantHits: filter.Hits{"include[0]": 2, "exclude[0]": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			got, hits := filter.Apply(&tt.config, records())
+
+			gotNames := make([]string, 0, len(got))
+			for _, r := range got {
+				gotNames = append(gotNames, r.Name)
+			}
+
+			if !reflect.DeepEqual(gotNames, tt.want) {
+				t.Errorf("Apply() got = %v, want %v", gotNames, tt.want)
+			}
+
+			if !reflect.DeepEqual(hits, tt.wantHits) {
+				t.Errorf("Apply() got hits = %v, want %v", hits, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestConfig_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  filter.Config
+		wantErr bool
+		wantIs  error
+	}{
+		{name: "empty", config: filter.Config{}},
+		{
+			name:    "empty rule",
+			config:  filter.Config{Exclude: []*filter.Rule{{Name: "empty"}}},
+			wantErr: true,
+			wantIs:  filter.ErrEmptyRule,
+		},
+		{
+			name:    "bad regex",
+			config:  filter.Config{Include: []*filter.Rule{{RoomRegex: "("}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Check()
+			if (err != nil) != tt.wantErr || tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package guard
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// defaults
+const (
+	DefaultMaxDrop = 30.0 // percent
+
+	stateFileName = "last_upload.json"
+)
+
+var (
+	ErrBadMaxDrop  = errors.New("drop must be a percent between 0 and 100 (max_doctors_drop, max_free_cells_drop options)")
+	ErrBadStateDir = errors.New("state directory must be writable (state_dir option)")
+)
+
+// Config of the mass-deletion guard, the guard is disabled if state directory is not set,
+// drop limits are pointers to tell zero tolerance from the default
+type Config struct {
+	StateDir string `yaml:"state_dir"`
+
+	MaxDoctorsDrop   *float64 `yaml:"max_doctors_drop"`
+	MaxFreeCellsDrop *float64 `yaml:"max_free_cells_drop"`
+}
+
+func (c *Config) Check() error {
+	if c.MaxDoctorsDrop == nil {
+		c.MaxDoctorsDrop = Percent(DefaultMaxDrop)
+	}
+
+	if c.MaxFreeCellsDrop == nil {
+		c.MaxFreeCellsDrop = Percent(DefaultMaxDrop)
+	}
+
+	for _, drop := range []float64{*c.MaxDoctorsDrop, *c.MaxFreeCellsDrop} {
+		if drop < 0 || drop > 100 {
+			return fmt.Errorf("%w: %v", ErrBadMaxDrop, drop)
+		}
+	}
+
+	if c.IsEnabled() {
+		return c.checkStateDir()
+	}
+
+	return nil
+}
+
+// checkStateDir creates the state directory if missing and makes sure the stats can be saved there,
+// otherwise the guard would never find the last upload and never block
+func (c *Config) checkStateDir() error {
+	if err := os.MkdirAll(c.StateDir, 0750); err != nil {
+		return fmt.Errorf("%w: %v", ErrBadStateDir, err)
+	}
+
+	probe, err := ioutil.TempFile(c.StateDir, stateFileName+".probe")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBadStateDir, err)
+	}
+
+	if err := probe.Close(); err != nil {
+		return fmt.Errorf("%w: %v", ErrBadStateDir, err)
+	}
+
+	return os.Remove(probe.Name())
+}
+
+// Percent returns pointer to the drop limit
+func Percent(drop float64) *float64 {
+	return &drop
+}
+
+func (c *Config) IsEnabled() bool {
+	return c.StateDir != ""
+}
+
+func (c *Config) stateFilename() string {
+	return filepath.Join(c.StateDir, stateFileName)
+}
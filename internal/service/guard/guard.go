@@ -0,0 +1,87 @@
+package guard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	ErrMassDeletion = errors.New("schedule shrank too much since the last successful upload")
+	ErrNotSaved     = errors.New("last upload stats not saved, the next schedule is compared to older stats")
+)
+
+// Stats of an uploaded schedule
+type Stats struct {
+	SessionID string    `json:"session_id"`
+	Time      time.Time `json:"time"`
+	Doctors   int       `json:"doctors"`
+	FreeCells int       `json:"free_cells"`
+}
+
+// LoadLast returns stats of the last successful upload, nil if there was no upload yet
+func LoadLast(config *Config) (*Stats, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(config.stateFilename()))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats := new(Stats)
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", config.stateFilename(), err)
+	}
+
+	return stats, nil
+}
+
+// SaveLast persists stats of the successful upload
+func SaveLast(config *Config, stats *Stats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+
+	tmpName := config.stateFilename() + ".tmp"
+
+	if err := ioutil.WriteFile(tmpName, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpName, config.stateFilename())
+}
+
+// dropPercent returns how much the current value decreased comparing to the last one
+func dropPercent(last int, current int) float64 {
+	if last <= 0 || current >= last {
+		return 0
+	}
+
+	return float64(last-current) * 100 / float64(last)
+}
+
+// Compare returns error if the number of doctors or free cells dropped more than allowed
+func Compare(config *Config, last *Stats, current *Stats) error {
+	if last == nil {
+		return nil
+	}
+
+	if drop := dropPercent(last.Doctors, current.Doctors); drop > *config.MaxDoctorsDrop {
+		return fmt.Errorf("%w: doctors %d -> %d (-%.0f%%), last upload %s",
+			ErrMassDeletion, last.Doctors, current.Doctors, drop, last.SessionID)
+	}
+
+	if drop := dropPercent(last.FreeCells, current.FreeCells); drop > *config.MaxFreeCellsDrop {
+		return fmt.Errorf("%w: free cells %d -> %d (-%.0f%%), last upload %s",
+			ErrMassDeletion, last.FreeCells, current.FreeCells, drop, last.SessionID)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package guard_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/guard"
+)
+
+func TestCompare_ZeroTolerance(t *testing.T) {
+	config := &guard.Config{MaxDoctorsDrop: guard.Percent(0)}
+	if err := config.Check(); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	last := &guard.Stats{SessionID: "20210701T100000", Doctors: 60, FreeCells: 1000}
+
+	err := guard.Compare(config, last, &guard.Stats{Doctors: 59, FreeCells: 1000})
+	if !errors.Is(err, guard.ErrMassDeletion) {
+		t.Errorf("Compare() error = %v, wantErr %v", err, guard.ErrMassDeletion)
+	}
+
+	if err := guard.Compare(config, last, &guard.Stats{Doctors: 60, FreeCells: 800}); err != nil {
+		t.Errorf("Compare() error = %v, wantErr nil", err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	config := &guard.Config{MaxDoctorsDrop: guard.Percent(50)}
+	if err := config.Check(); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	last := &guard.Stats{SessionID: "20210701T100000", Doctors: 60, FreeCells: 1000}
+
+	tests := []struct {
+		name    string
+		last    *guard.Stats
+		current *guard.Stats
+		wantErr error
+	}{
+		{name: "first upload", last: nil, current: &guard.Stats{}, wantErr: nil},
+		{name: "grown", last: last, current: &guard.Stats{Doctors: 70, FreeCells: 1200}, wantErr: nil},
+		{name: "doctors dropped", last: last, current: &guard.Stats{Doctors: 20, FreeCells: 1000}, wantErr: guard.ErrMassDeletion},
+		{name: "doctors within limit", last: last, current: &guard.Stats{Doctors: 30, FreeCells: 1000}, wantErr: nil},
+		{name: "free cells dropped", last: last, current: &guard.Stats{Doctors: 60, FreeCells: 600}, wantErr: guard.ErrMassDeletion},
+		{name: "free cells within limit", last: last, current: &guard.Stats{Doctors: 60, FreeCells: 700}, wantErr: nil},
+		{name: "empty", last: last, current: &guard.Stats{}, wantErr: guard.ErrMassDeletion},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := guard.Compare(config, tt.last, tt.current); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSaveLast(t *testing.T) {
+	config := &guard.Config{StateDir: t.TempDir()}
+
+	got, err := guard.LoadLast(config)
+	if err != nil || got != nil {
+		t.Fatalf("LoadLast() got = %v, error = %v, want nothing", got, err)
+	}
+
+	want := &guard.Stats{
+		SessionID: "20210701T100000",
+		Time:      time.Date(2021, 7, 1, 10, 0, 0, 0, time.UTC),
+		Doctors:   60,
+		FreeCells: 1000,
+	}
+
+	if err := guard.SaveLast(config, want); err != nil {
+		t.Fatalf("SaveLast() error = %v", err)
+	}
+
+	got, err = guard.LoadLast(config)
+	if err != nil {
+		t.Fatalf("LoadLast() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadLast() got = %v, want %v", got, want)
+	}
+}
+
+func TestConfig_CheckStateDir(t *testing.T) {
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := ioutil.WriteFile(notDir, []byte{}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		stateDir string
+		wantErr  error
+	}{
+		{name: "disabled", stateDir: "", wantErr: nil},
+		{name: "missing", stateDir: filepath.Join(t.TempDir(), "var", "lib", "prodoctorov"), wantErr: nil},
+		{name: "not a directory", stateDir: filepath.Join(notDir, "prodoctorov"), wantErr: guard.ErrBadStateDir},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			config := &guard.Config{StateDir: tt.stateDir}
+			if err := config.Check(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && tt.stateDir != "" {
+				if err := guard.SaveLast(config, &guard.Stats{SessionID: "20210701T100000"}); err != nil {
+					t.Errorf("SaveLast() error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package hours
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// actions on time cells outside working hours
+const (
+	ActionDrop = "drop" // cells are not published and reported
+	ActionFlag = "flag" // cells are published and reported
+
+	DefaultAction = ActionDrop
+
+	ClockLayout = "15:04"
+)
+
+var (
+	ErrBadAction  = errors.New("action must be one of drop, flag (working_hours.action option)")
+	ErrBadWeekday = errors.New("weekday must be an English day name, e.g. monday (working_hours option)")
+	ErrBadHours   = errors.New("hours must be as 08:00-20:00 (working_hours option)")
+)
+
+// Hours working hours by weekday, e.g. monday: 08:00-20:00; a day not listed is a day off
+type Hours map[string]string
+
+type span struct {
+	open  time.Duration // since midnight
+	close time.Duration
+}
+
+type week map[time.Weekday]span
+
+// Config working hours of the filials, cells outside them are dropped or flagged
+type Config struct {
+	Action  string           `yaml:"action"`
+	Default Hours            `yaml:"default"` // filials w/o own hours and the single filial
+	Filials map[string]Hours `yaml:"filials"` // by filial identifier, see filials.Config
+
+	defaultWeek week
+	filialWeeks map[string]week
+}
+
+func (c *Config) IsEnabled() bool {
+	return len(c.Default) != 0 || len(c.Filials) != 0
+}
+
+func (c *Config) Check() error {
+	if c.Action == "" {
+		c.Action = DefaultAction
+	}
+
+	switch c.Action {
+	case ActionDrop, ActionFlag:
+	default:
+		return fmt.Errorf("%w: %s", ErrBadAction, c.Action)
+	}
+
+	var err error
+
+	if c.defaultWeek, err = parseWeek(c.Default); err != nil {
+		return err
+	}
+
+	c.filialWeeks = make(map[string]week, len(c.Filials))
+
+	for filial, hours := range c.Filials {
+		if c.filialWeeks[filial], err = parseWeek(hours); err != nil {
+			return fmt.Errorf("%w: filial %s", err, filial)
+		}
+	}
+
+	return nil
+}
+
+func parseWeek(hours Hours) (week, error) {
+	result := make(week, len(hours))
+
+	for name, value := range hours {
+		day, err := parseWeekday(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if result[day], err = parseSpan(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), strings.TrimSpace(name)) {
+			return day, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrBadWeekday, name)
+}
+
+func parseSpan(value string) (span, error) {
+	open, closeTime, ok := strings.Cut(value, "-")
+	if !ok {
+		return span{}, fmt.Errorf("%w: %s", ErrBadHours, value)
+	}
+
+	var (
+		result span
+		err    error
+	)
+
+	if result.open, err = parseClock(open); err != nil {
+		return span{}, fmt.Errorf("%w: %s", ErrBadHours, value)
+	}
+
+	if result.close, err = parseClock(closeTime); err != nil {
+		return span{}, fmt.Errorf("%w: %s", ErrBadHours, value)
+	}
+
+	if result.close == 0 {
+		result.close = 24 * time.Hour // open until midnight
+	}
+
+	if result.close <= result.open {
+		return span{}, fmt.Errorf("%w: %s", ErrBadHours, value)
+	}
+
+	return result, nil
+}
+
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse(ClockLayout, strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,71 @@
+package hours
+
+import (
+	"time"
+
+	"prodoctorov/internal/service/domino"
+)
+
+// week returns working hours of the filial, nil if not configured
+func (c *Config) week(filial string) week {
+	if w, ok := c.filialWeeks[filial]; ok {
+		return w
+	}
+
+	if len(c.defaultWeek) != 0 {
+		return c.defaultWeek
+	}
+
+	return nil
+}
+
+// isWithin reports whether the cell starts and ends within working hours of its day
+func (w week) isWithin(cell *domino.TimeCell) bool {
+	hours, ok := w[cell.StartTime.Weekday()]
+	if !ok {
+		return false
+	}
+
+	y, m, d := cell.StartTime.Date()
+	start := cell.StartTime.Sub(time.Date(y, m, d, 0, 0, 0, 0, cell.StartTime.Location()))
+
+	return start >= hours.open && start+cell.Duration <= hours.close
+}
+
+// Apply checks time cells of the doctor against working hours of the filial, cells outside them
+// are dropped if the action is "drop"; returns such cells
+func Apply(config *Config, schedule *domino.DoctorSchedule) []*domino.OutOfHoursCell {
+	w := config.week(schedule.Filial)
+	if w == nil {
+		return nil
+	}
+
+	outside := make([]*domino.OutOfHoursCell, 0)
+	result := make(domino.TimeCells, 0, len(schedule.Cells))
+
+	for _, cell := range schedule.Cells {
+		if w.isWithin(cell) {
+			result = append(result, cell)
+
+			continue
+		}
+
+		outside = append(outside, &domino.OutOfHoursCell{
+			Spec:      schedule.Spec,
+			Name:      schedule.Name,
+			DoctorID:  schedule.ID,
+			Filial:    schedule.Filial,
+			StartTime: cell.StartTime,
+			EndTime:   cell.StartTime.Add(cell.Duration),
+			Action:    config.Action,
+		})
+
+		if config.Action == ActionFlag {
+			result = append(result, cell)
+		}
+	}
+
+	schedule.Cells = result
+
+	return outside
+}
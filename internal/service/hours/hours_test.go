@@ -0,0 +1,160 @@
+package hours_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/hours"
+)
+
+func cell(day, hour, minute int, duration time.Duration) *domino.TimeCell {
+	return &domino.TimeCell{
+		StartTime: time.Date(2021, 7, day, hour, minute, 0, 0, time.UTC), // 2021-07-03 is Saturday
+		Duration:  duration,
+		Free:      true,
+	}
+}
+
+func TestApply(t *testing.T) {
+	cells := func() domino.TimeCells {
+		return domino.TimeCells{
+			cell(1, 1, 0, 20*time.Minute),
+			cell(1, 13, 0, 20*time.Minute),
+			cell(1, 19, 50, 20*time.Minute),
+			cell(3, 10, 0, 20*time.Minute),
+			cell(4, 10, 0, 20*time.Minute),
+		}
+	}
+
+	config := hours.Config{
+		Default: hours.Hours{
+			"monday": "08:00-20:00", "tuesday": "08:00-20:00", "wednesday": "08:00-20:00",
+			"thursday": "08:00-20:00", "friday": "08:00-20:00",
+		},
+		Filials: map[string]hours.Hours{
+			"north": {"Thursday": "08:00-00:00", "saturday": "09:00-15:00"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		action      string
+		filial      string
+		want        domino.TimeCells
+		wantOutside int
+	}{
+		{
+			name:        "drop",
+			action:      hours.ActionDrop,
+			want:        domino.TimeCells{cell(1, 13, 0, 20*time.Minute)},
+			wantOutside: 4,
+		},
+		{
+			name:        "flag",
+			action:      hours.ActionFlag,
+			want:        cells(),
+			wantOutside: 4,
+		},
+		{
+			name:   "filial",
+			action: hours.ActionDrop,
+			filial: "north",
+			want: domino.TimeCells{
+				cell(1, 13, 0, 20*time.Minute),
+				cell(1, 19, 50, 20*time.Minute),
+				cell(3, 10, 0, 20*time.Minute),
+			},
+			wantOutside: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			config := config
+			config.Action = tt.action
+
+			if err := config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			schedule := &domino.DoctorSchedule{
+				Spec:   "Терапевт",
+				Name:   "Иванов И.И.",
+				Filial: tt.filial,
+				Cells:  cells(),
+			}
+
+			outside := hours.Apply(&config, schedule)
+			if len(outside) != tt.wantOutside {
+				t.Errorf("Apply() got outside = %d, want %d", len(outside), tt.wantOutside)
+			}
+
+			for _, c := range outside {
+				if c.Name != schedule.Name || c.Filial != tt.filial || c.Action != tt.action ||
+					c.EndTime.Sub(c.StartTime) != 20*time.Minute {
+					t.Errorf("Apply() got outside cell = %+v", c)
+				}
+			}
+
+			if !reflect.DeepEqual(schedule.Cells, tt.want) {
+				for i, c := range schedule.Cells {
+					t.Errorf("Got item %d: %v", i, c)
+				}
+
+				t.Errorf("Apply() got %d cells, want %d", len(schedule.Cells), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestApply_Disabled(t *testing.T) {
+	config := hours.Config{}
+	if err := config.Check(); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	schedule := &domino.DoctorSchedule{Cells: domino.TimeCells{cell(1, 1, 0, 20*time.Minute)}}
+	if outside := hours.Apply(&config, schedule); len(outside) != 0 || len(schedule.Cells) != 1 {
+		t.Errorf("Apply() got outside = %v, cells = %d", outside, len(schedule.Cells))
+	}
+}
+
+func TestConfig_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  hours.Config
+		wantErr error
+	}{
+		{name: "bad action", config: hours.Config{Action: "warn"}, wantErr: hours.ErrBadAction},
+		{
+			name:    "bad weekday",
+			config:  hours.Config{Default: hours.Hours{"пн": "08:00-20:00"}},
+			wantErr: hours.ErrBadWeekday,
+		},
+		{
+			name:    "bad hours",
+			config:  hours.Config{Default: hours.Hours{"monday": "08:00"}},
+			wantErr: hours.ErrBadHours,
+		},
+		{
+			name:    "close before open",
+			config:  hours.Config{Filials: map[string]hours.Hours{"north": {"monday": "20:00-08:00"}}},
+			wantErr: hours.ErrBadHours,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Check(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
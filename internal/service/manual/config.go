@@ -0,0 +1,74 @@
+package manual
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"prodoctorov/internal/service/dominocsv"
+)
+
+// policies of doctors present both in Domino and in the manual schedule
+const (
+	PreferDomino = "domino" // manual records of the doctor are ignored
+	PreferManual = "manual" // Domino records of the doctor are replaced by manual ones
+	PreferMerge  = "merge"  // records are merged, overlapped time cells are resolved by domino.conflicts policy
+
+	DefaultPrefer = PreferDomino
+)
+
+// formats of the manual schedule file
+const (
+	FormatCSV  = "csv"
+	FormatYAML = "yaml"
+)
+
+var (
+	ErrBadPrefer = errors.New("policy must be one of domino, manual, merge (manual.prefer option)")
+	ErrBadFormat = errors.New("format must be one of csv, yaml (manual.format option)")
+)
+
+// Config of the manual schedule source, the file is read every upload session
+type Config struct {
+	File   string           `yaml:"file"`   // missing file means no manual records
+	Format string           `yaml:"format"` // by default by the file extension, CSV if unknown
+	CSV    dominocsv.Config `yaml:"csv"`    // CSV dialect of the file
+	Prefer string           `yaml:"prefer"`
+}
+
+func (c *Config) IsEnabled() bool {
+	return c.File != ""
+}
+
+func (c *Config) Check() error {
+	if c.Prefer == "" {
+		c.Prefer = DefaultPrefer
+	}
+
+	switch c.Prefer {
+	case PreferDomino, PreferManual, PreferMerge:
+	default:
+		return fmt.Errorf("%w: %s", ErrBadPrefer, c.Prefer)
+	}
+
+	if c.Format == "" {
+		c.Format = FormatCSV
+
+		if ext := strings.ToLower(filepath.Ext(c.File)); ext == ".yaml" || ext == ".yml" {
+			c.Format = FormatYAML
+		}
+	}
+
+	switch c.Format {
+	case FormatCSV, FormatYAML:
+	default:
+		return fmt.Errorf("%w: %s", ErrBadFormat, c.Format)
+	}
+
+	if err := c.CSV.Check(); err != nil {
+		return fmt.Errorf("bad csv dialect: %w", err)
+	}
+
+	return nil
+}
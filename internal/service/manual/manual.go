@@ -0,0 +1,149 @@
+package manual
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/dominocsv"
+)
+
+// Row a record of the manual schedule in YAML, fields are the same as the columns of the Domino export
+type Row struct {
+	Spec     string `yaml:"spec"`
+	Name     string `yaml:"name"`
+	Cell     string `yaml:"cell"`
+	Duration string `yaml:"duration"` // minutes
+	Free     string `yaml:"free"`
+	Room     string `yaml:"room"`
+	DoctorID string `yaml:"doctor_id"`
+}
+
+// yamlToCSV converts YAML rows to CSV with the header, so the rows are validated as the Domino export ones
+func yamlToCSV(data []byte) ([]byte, error) {
+	rows := make([]*Row, 0)
+	if err := yaml.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write([]string{"spec", "name", "cell", "duration", "free", "room", "doctor_id"}); err != nil {
+		return nil, err
+	}
+
+	for _, r := range rows {
+		if err := writer.Write([]string{r.Spec, r.Name, r.Cell, r.Duration, r.Free, r.Room, r.DoctorID}); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+
+	return buf.Bytes(), writer.Error()
+}
+
+// Load reads the manual schedule, rows are validated by domino.NewRecord with the Domino config;
+// no records if the file is not found
+func Load(
+	config *Config,
+	dominoConfig *domino.Config,
+	timeNow time.Time,
+	log domino.LogMalformedRecord,
+) (domino.Records, *domino.ImportReport, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(config.File))
+	if errors.Is(err, os.ErrNotExist) {
+		return domino.Records{}, domino.NewImportReport(), nil
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	importConfig := *dominoConfig
+	importConfig.CSV = config.CSV
+
+	if config.Format == FormatYAML {
+		if data, err = yamlToCSV(data); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode %s: %w", config.File, err)
+		}
+
+		importConfig.CSV = dominocsv.Config{}
+	}
+
+	records, report, err := domino.ImportRecords(data, &importConfig, timeNow, log)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode %s: %w", config.File, err)
+	}
+
+	return records, report, nil
+}
+
+// DoctorKey identifies the doctor of a record in both sources, e.g. by mapped specialty and name
+type DoctorKey func(r *domino.Record) string
+
+// Merge adds manual records to Domino ones according to the policy, doctors are matched by the key
+// or by Record.ID if the key is nil; returns the records and sorted keys of doctors present in both sources
+func Merge(
+	config *Config,
+	key DoctorKey,
+	dominoRecords domino.Records,
+	manualRecords domino.Records,
+) (domino.Records, []string) {
+	if key == nil {
+		key = func(r *domino.Record) string {
+			return r.ID()
+		}
+	}
+
+	dominoIDs := make(map[string]struct{})
+	for _, r := range dominoRecords {
+		dominoIDs[key(r)] = struct{}{}
+	}
+
+	manualIDs := make(map[string]struct{})
+	for _, r := range manualRecords {
+		manualIDs[key(r)] = struct{}{}
+	}
+
+	both := make([]string, 0)
+
+	for id := range manualIDs {
+		if _, ok := dominoIDs[id]; ok {
+			both = append(both, id)
+		}
+	}
+
+	sort.Strings(both)
+
+	result := make(domino.Records, 0, len(dominoRecords)+len(manualRecords))
+
+	for _, r := range dominoRecords {
+		if _, ok := manualIDs[key(r)]; ok && config.Prefer == PreferManual {
+			continue
+		}
+
+		result = append(result, r)
+	}
+
+	for _, r := range manualRecords {
+		if _, ok := dominoIDs[key(r)]; ok && config.Prefer == PreferDomino {
+			continue
+		}
+
+		result = append(result, r)
+	}
+
+	return result, both
+}
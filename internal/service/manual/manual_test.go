@@ -0,0 +1,156 @@
+package manual_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/manual"
+	"prodoctorov/internal/service/names"
+)
+
+const manualCSV = `spec;name;cell;duration;free;room;doctor_id
+Кардиолог;Сергеев А.В.;10.11.21 10:00:00;30;free;5 кабинет;
+Кардиолог;;10.11.21 10:30:00;30;free;5 кабинет;
+`
+
+const manualYAML = `
+- {spec: Кардиолог, name: Сергеев А.В., cell: "10.11.21 10:00:00", duration: "30", free: free, room: 5 кабинет}
+- {spec: Кардиолог, name: Сергеев А.В., cell: "10.11.21 10:30", duration: "30", free: free, room: 5 кабинет}
+`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		file     string
+		data     string
+		wantLine int
+	}{
+		{name: "csv", file: "manual.csv", data: manualCSV, wantLine: 3},
+		{name: "yaml", file: "manual.yaml", data: manualYAML, wantLine: 3},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			config := manual.Config{File: filepath.Join(dir, tt.file)}
+			config.CSV.Delimiter = ";"
+
+			if err := ioutil.WriteFile(config.File, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			records, report, err := manual.Load(
+				&config,
+				&domino.Config{},
+				time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC),
+				func(message string) {
+					t.Log(message)
+				},
+			)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			want := domino.Records{{
+				Spec:      "Кардиолог",
+				Name:      "Сергеев А.В.",
+				StartTime: time.Date(2021, 11, 10, 10, 0, 0, 0, time.UTC),
+				Duration:  30 * time.Minute,
+				Free:      true,
+				Room:      "5 кабинет",
+			}}
+
+			if !reflect.DeepEqual(records, want) {
+				t.Errorf("Load() got = %v, want %v", records, want)
+			}
+
+			if report.RejectedCount() != 1 || report.Rejected[0].Line != tt.wantLine {
+				t.Errorf("Load() got rejected = %v", report.Rejected)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dominoRecords := domino.Records{
+		{Spec: "Терапевт", Name: "Иванов И.И.", Room: "domino"},
+		{Spec: "Кардиолог", Name: "Сергеев А.В.", Room: "domino"},
+	}
+	manualRecords := domino.Records{
+		{Spec: "Кардиолог", Name: "Сергеев  А. В.", Room: "manual"},
+		{Spec: "Невролог", Name: "Козлов К.К.", Room: "manual"},
+	}
+
+	key := func(r *domino.Record) string {
+		return r.Spec + "/" + names.Normalize(r.Name)
+	}
+
+	tests := []struct {
+		name     string
+		prefer   string
+		key      manual.DoctorKey
+		want     []string
+		wantBoth []string
+	}{
+		{
+			name:     "domino",
+			prefer:   manual.PreferDomino,
+			key:      key,
+			want:     []string{"Терапевт/domino", "Кардиолог/domino", "Невролог/manual"},
+			wantBoth: []string{"Кардиолог/Сергеев А.В."},
+		},
+		{
+			name:     "manual",
+			prefer:   manual.PreferManual,
+			key:      key,
+			want:     []string{"Терапевт/domino", "Кардиолог/manual", "Невролог/manual"},
+			wantBoth: []string{"Кардиолог/Сергеев А.В."},
+		},
+		{
+			name:     "merge",
+			prefer:   manual.PreferMerge,
+			key:      key,
+			want:     []string{"Терапевт/domino", "Кардиолог/domino", "Кардиолог/manual", "Невролог/manual"},
+			wantBoth: []string{"Кардиолог/Сергеев А.В."},
+		},
+		{
+			name:     "raw id",
+			prefer:   manual.PreferManual,
+			key:      nil,
+			want:     []string{"Терапевт/domino", "Кардиолог/domino", "Кардиолог/manual", "Невролог/manual"},
+			wantBoth: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, both := manual.Merge(&manual.Config{Prefer: tt.prefer}, tt.key, dominoRecords, manualRecords)
+
+			gotSources := make([]string, 0, len(got))
+			for _, r := range got {
+				gotSources = append(gotSources, r.Spec+"/"+r.Room)
+			}
+
+			if !reflect.DeepEqual(gotSources, tt.want) {
+				t.Errorf("Merge() got = %v, want %v", gotSources, tt.want)
+			}
+
+			if !reflect.DeepEqual(both, tt.wantBoth) {
+				t.Errorf("Merge() got both = %v, want %v", both, tt.wantBoth)
+			}
+		})
+	}
+}
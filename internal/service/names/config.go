@@ -0,0 +1,46 @@
+package names
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config of doctor names processing
+type Config struct {
+	Normalize bool `yaml:"normalize"`
+
+	// AliasesFile YAML file mapping Domino names to canonical names ("efio" of prodoctorov)
+	AliasesFile string `yaml:"aliases_file"`
+	aliases     map[string]string
+}
+
+func (c *Config) Check() error {
+	if c.AliasesFile == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(filepath.Clean(c.AliasesFile))
+	if err != nil {
+		return err
+	}
+
+	aliases := make(map[string]string)
+	if err := yaml.Unmarshal(data, &aliases); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", c.AliasesFile, err)
+	}
+
+	c.aliases = make(map[string]string, len(aliases))
+
+	for name, canonical := range aliases {
+		c.aliases[Normalize(name)] = canonical
+	}
+
+	return nil
+}
+
+func (c *Config) hasAliases() bool {
+	return c.AliasesFile != ""
+}
@@ -0,0 +1,92 @@
+package names
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"prodoctorov/internal/service/domino"
+)
+
+var (
+	initialsRe = regexp.MustCompile(`(\p{L})\.\s+(\p{L})\.`)
+
+	yoReplacer = strings.NewReplacer("ё", "е", "Ё", "Е")
+)
+
+// capitalized returns the word with the first letter in upper case and the rest in lower case
+func capitalized(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+
+	return string(unicode.ToUpper(r)) + strings.ToLower(word[size:])
+}
+
+// Normalize brings a doctor's name to the form "Фамилия И.О.": collapses whitespace,
+// replaces ё by е, removes spaces between initials and fixes letter case
+func Normalize(name string) string {
+	name = strings.Join(strings.Fields(yoReplacer.Replace(name)), " ")
+	name = initialsRe.ReplaceAllString(name, "$1.$2.")
+
+	words := strings.Split(name, " ")
+
+	for i, word := range words {
+		if strings.Contains(word, ".") { // initials
+			words[i] = strings.ToUpper(word)
+
+			continue
+		}
+
+		parts := strings.Split(word, "-") // double-barrelled surname
+		for j, part := range parts {
+			if part != "" {
+				parts[j] = capitalized(part)
+			}
+		}
+
+		words[i] = strings.Join(parts, "-")
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Canonical returns the canonical name of the doctor, false if there is no alias for it
+func (c *Config) Canonical(name string) (string, bool) {
+	normalized := Normalize(name)
+
+	if canonical, ok := c.aliases[normalized]; ok {
+		return canonical, true
+	}
+
+	if c.Normalize {
+		return normalized, false
+	}
+
+	return name, false
+}
+
+// Apply replaces names of the records by the canonical ones, returns sorted names w/o alias;
+// nothing is reported if aliases file is not configured
+func Apply(config *Config, records domino.Records) []string {
+	unmapped := make(map[string]struct{})
+
+	for _, r := range records {
+		canonical, ok := config.Canonical(r.Name)
+		if !ok && config.hasAliases() {
+			unmapped[r.Name] = struct{}{}
+		}
+
+		r.KeepDominoValues()
+		r.Name = canonical
+	}
+
+	result := make([]string, 0, len(unmapped))
+	for name := range unmapped {
+		result = append(result, name)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,107 @@
+package names_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/names"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Иванов Е.А.", want: "Иванов Е.А."},
+		{name: " Иванов  Е. А. ", want: "Иванов Е.А."},
+		{name: "Иванов Ё.А.", want: "Иванов Е.А."},
+		{name: "ИВАНОВ е.а.", want: "Иванов Е.А."},
+		{name: "Соловьёва-седых Н.\tВ.", want: "Соловьева-Седых Н.В."},
+		{name: "Иванов Евгений Александрович", want: "Иванов Евгений Александрович"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := names.Normalize(tt.name); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	aliasesFile := filepath.Join(t.TempDir(), "aliases.yaml")
+
+	aliases := []byte(`"Иванов Е. А.": "Иванов Евгений Александрович"` + "\n")
+	if err := ioutil.WriteFile(aliasesFile, aliases, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		config       names.Config
+		want         []string
+		wantUnmapped []string
+	}{
+		{
+			name:         "disabled",
+			config:       names.Config{},
+			want:         []string{"Иванов Е.А.", "Иванов  Е. А.", "Иванов Ё.А.", "ПЕТРОВ Г.А."},
+			wantUnmapped: []string{},
+		},
+		{
+			name:         "normalize",
+			config:       names.Config{Normalize: true},
+			want:         []string{"Иванов Е.А.", "Иванов Е.А.", "Иванов Е.А.", "Петров Г.А."},
+			wantUnmapped: []string{},
+		},
+		{
+			name:   "aliases",
+			config: names.Config{AliasesFile: aliasesFile},
+			want: []string{
+				"Иванов Евгений Александрович",
+				"Иванов Евгений Александрович",
+				"Иванов Евгений Александрович",
+				"ПЕТРОВ Г.А.",
+			},
+			wantUnmapped: []string{"ПЕТРОВ Г.А."},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			records := domino.Records{
+				{Spec: "Гастроэнтеролог", Name: "Иванов Е.А."},
+				{Spec: "Гастроэнтеролог", Name: "Иванов  Е. А."},
+				{Spec: "Гастроэнтеролог", Name: "Иванов Ё.А."},
+				{Spec: "Уролог", Name: "ПЕТРОВ Г.А."},
+			}
+
+			gotUnmapped := names.Apply(&tt.config, records)
+
+			got := make([]string, 0, len(records))
+			for _, r := range records {
+				got = append(got, r.Name)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() got names = %q, want %q", got, tt.want)
+			}
+
+			if !reflect.DeepEqual(gotUnmapped, tt.wantUnmapped) {
+				t.Errorf("Apply() got unmapped = %q, want %q", gotUnmapped, tt.wantUnmapped)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package overrides
+
+// Config of doctor overrides, the file is read every upload session
+type Config struct {
+	File string `yaml:"file"` // YAML list of overrides, missing file means no overrides
+}
+
+func (c *Config) Check() error {
+	if _, err := c.Load(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Load reads overrides from the file, no overrides if the file is not set or not found
+func (c *Config) Load() (Overrides, error) {
+	if c.File == "" {
+		return Overrides{}, nil
+	}
+
+	return Load(c.File)
+}
@@ -0,0 +1,183 @@
+package overrides
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/names"
+)
+
+// DateLayout layout of override dates
+const DateLayout = "2006-01-02"
+
+// actions on time cells of the doctor
+const (
+	ActionHide = "hide" // cells are not published
+	ActionBusy = "busy" // cells are published as busy
+)
+
+var (
+	ErrBadOverride = errors.New("override must have doctor, from and to dates and action hide or busy")
+	ErrBadRange    = errors.New("override ends before it starts")
+)
+
+// Override hides doctor's time cells or marks them busy in the days range, both ends included,
+// e.g. on sick leave; the override expires after the range
+type Override struct {
+	Doctor  string `yaml:"doctor"` // Domino doctor's name in any spelling or external identifier
+	From    string `yaml:"from"`
+	To      string `yaml:"to"`
+	Action  string `yaml:"action"`
+	Comment string `yaml:"comment,omitempty"`
+
+	doctor string // normalized name, see names.Normalize
+	from   time.Time
+	to     time.Time
+}
+
+func (o *Override) String() string {
+	return fmt.Sprintf("%s %s..%s %s", o.Doctor, o.From, o.To, o.Action)
+}
+
+func (o *Override) check() error {
+	if o.Doctor == "" || (o.Action != ActionHide && o.Action != ActionBusy) {
+		return fmt.Errorf("%w: %s", ErrBadOverride, o)
+	}
+
+	var err error
+
+	o.doctor = names.Normalize(o.Doctor)
+
+	if o.from, err = time.Parse(DateLayout, o.From); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrBadOverride, o, err)
+	}
+
+	if o.to, err = time.Parse(DateLayout, o.To); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrBadOverride, o, err)
+	}
+
+	if o.to.Before(o.from) {
+		return fmt.Errorf("%w: %s", ErrBadRange, o)
+	}
+
+	return nil
+}
+
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Match reports whether the record is of the doctor and starts within the range,
+// names are compared regardless of spelling variants
+func (o *Override) Match(record *domino.Record) bool {
+	if o.doctor != names.Normalize(record.Name) && o.Doctor != record.DoctorID {
+		return false
+	}
+
+	startDay := day(record.StartTime)
+
+	return !startDay.Before(o.from) && !startDay.After(o.to)
+}
+
+// IsExpired reports whether the range is over, timeNow is a wall clock time
+func (o *Override) IsExpired(timeNow time.Time) bool {
+	return day(timeNow).After(o.to)
+}
+
+type Overrides []*Override
+
+// Load reads and validates the overrides file, missing file means no overrides
+func Load(fileName string) (Overrides, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(fileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return Overrides{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(Overrides, 0)
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", fileName, err)
+	}
+
+	for _, override := range overrides {
+		if err := override.check(); err != nil {
+			return nil, fmt.Errorf("bad override in %s: %w", fileName, err)
+		}
+	}
+
+	return overrides, nil
+}
+
+// Active returns overrides not expired yet
+func (o Overrides) Active(timeNow time.Time) Overrides {
+	result := make(Overrides, 0, len(o))
+
+	for _, override := range o {
+		if !override.IsExpired(timeNow) {
+			result = append(result, override)
+		}
+	}
+
+	return result
+}
+
+// Apply hides records of overridden doctors or marks them busy, the first override matched wins;
+// returns the records left, the number of records affected and the overrides matched no record
+func Apply(overrides Overrides, records domino.Records) (domino.Records, int, Overrides) {
+	if len(overrides) == 0 {
+		return records, 0, Overrides{}
+	}
+
+	affected := 0
+	matched := make(map[*Override]struct{}, len(overrides))
+	result := make(domino.Records, 0, len(records))
+
+	for _, r := range records {
+		override := overrides.match(r)
+		if override == nil {
+			result = append(result, r)
+
+			continue
+		}
+
+		affected++
+		matched[override] = struct{}{}
+
+		if override.Action == ActionBusy {
+			r.Free = false
+			result = append(result, r)
+		}
+	}
+
+	unmatched := make(Overrides, 0)
+
+	for _, override := range overrides {
+		if _, ok := matched[override]; !ok {
+			unmatched = append(unmatched, override)
+		}
+	}
+
+	return result, affected, unmatched
+}
+
+func (o Overrides) match(record *domino.Record) *Override {
+	for _, override := range o {
+		if override.Match(record) {
+			return override
+		}
+	}
+
+	return nil
+}
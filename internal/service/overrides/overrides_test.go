@@ -0,0 +1,178 @@
+package overrides_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/overrides"
+)
+
+func writeOverrides(t *testing.T, data string) string {
+	t.Helper()
+
+	fileName := filepath.Join(t.TempDir(), "overrides.yaml")
+	if err := ioutil.WriteFile(fileName, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return fileName
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		record        *domino.Record
+		wantFree      []bool
+		wantAffected  int
+		wantUnmatched int
+	}{
+		{
+			name: "hide by id",
+			data: `[{doctor: "1001", from: 2021-11-01, to: 2021-11-02, action: hide, comment: больничный}]`,
+			record: &domino.Record{
+				Spec: "Терапевт", Name: "Иванов И.И.", DoctorID: "1001",
+				StartTime: time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree:     []bool{},
+			wantAffected: 1,
+		},
+		{
+			name: "busy by name",
+			data: `[{doctor: Козлов К.К., from: 2021-12-31, to: 2022-01-09, action: busy}]`,
+			record: &domino.Record{
+				Spec: "Уролог", Name: "Козлов К.К.",
+				StartTime: time.Date(2022, 1, 9, 23, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree:     []bool{false},
+			wantAffected: 1,
+		},
+		{
+			name: "out of range",
+			data: `[{doctor: "1001", from: 2021-11-01, to: 2021-11-02, action: hide}]`,
+			record: &domino.Record{
+				Spec: "Терапевт", Name: "Иванов И.И.", DoctorID: "1001",
+				StartTime: time.Date(2021, 11, 3, 10, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree:      []bool{true},
+			wantUnmatched: 1,
+		},
+		{
+			name: "name spelling",
+			data: `[{doctor: семенов  с. с., from: 2021-11-01, to: 2021-11-02, action: hide}]`,
+			record: &domino.Record{
+				Spec: "Терапевт", Name: "Семёнов С.С.",
+				StartTime: time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree:     []bool{},
+			wantAffected: 1,
+		},
+		{
+			name: "other doctor",
+			data: `[{doctor: Козлов К.К., from: 2021-11-01, to: 2021-11-02, action: hide}]`,
+			record: &domino.Record{
+				Spec: "Терапевт", Name: "Петров П.П.",
+				StartTime: time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC), Free: true,
+			},
+			wantFree:      []bool{true},
+			wantUnmatched: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			config := overrides.Config{File: writeOverrides(t, tt.data)}
+			if err := config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			loaded, err := config.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			got, affected, unmatched := overrides.Apply(loaded, domino.Records{tt.record})
+
+			gotFree := make([]bool, 0, len(got))
+			for _, r := range got {
+				gotFree = append(gotFree, r.Free)
+			}
+
+			if !reflect.DeepEqual(gotFree, tt.wantFree) || affected != tt.wantAffected {
+				t.Errorf("Apply() got = %v, %d, want %v, %d", gotFree, affected, tt.wantFree, tt.wantAffected)
+			}
+
+			if len(unmatched) != tt.wantUnmatched {
+				t.Errorf("Apply() got unmatched = %v, want %d", unmatched, tt.wantUnmatched)
+			}
+		})
+	}
+}
+
+func TestOverrides_Active(t *testing.T) {
+	config := overrides.Config{File: writeOverrides(t, `
+- {doctor: Иванов И.И., from: 2021-11-01, to: 2021-11-05, action: hide}
+- {doctor: "1001", from: 2021-10-01, to: 2021-10-05, action: busy}
+`)}
+
+	if err := config.Check(); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	loaded, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	active := loaded.Active(time.Date(2021, 11, 4, 12, 0, 0, 0, time.UTC))
+	if len(active) != 1 || active[0].Doctor != "Иванов И.И." {
+		t.Errorf("Active() got = %v", active)
+	}
+}
+
+func TestConfig_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{
+			name:    "no action",
+			data:    `[{doctor: Иванов И.И., from: 2021-11-01, to: 2021-11-05}]`,
+			wantErr: overrides.ErrBadOverride,
+		},
+		{
+			name:    "bad date",
+			data:    `[{doctor: "1001", from: 01.11.2021, to: 2021-11-05, action: hide}]`,
+			wantErr: overrides.ErrBadOverride,
+		},
+		{
+			name:    "bad range",
+			data:    `[{doctor: "1001", from: 2021-11-05, to: 2021-11-01, action: busy}]`,
+			wantErr: overrides.ErrBadRange,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			config := overrides.Config{File: writeOverrides(t, tt.data)}
+			if err := config.Check(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	missing := overrides.Config{File: filepath.Join(t.TempDir(), "missing.yaml")}
+	if err := missing.Check(); err != nil {
+		t.Errorf("Check() error = %v for missing file", err)
+	}
+}
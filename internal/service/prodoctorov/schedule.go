@@ -9,11 +9,16 @@ import (
 )
 
 var (
-	ErrStartAfterEnd = errors.New("wrong meeting time, start after end")
+	ErrStartAfterEnd   = errors.New("wrong meeting time, start after end")
+	ErrDuplicateDoctor = errors.New("doctor's schedule already added")
+	ErrUnknownFilial   = errors.New("unknown filial")
 )
 
 const singleFilial = "filial_id"
 
+// endOfDay end time of a cell ending at midnight, the time range must not cross the date
+const endOfDay = "23:59"
+
 type timeCellDto struct {
 	Date      string `json:"dt"`
 	TimeStart string `json:"time_start"`
@@ -44,14 +49,23 @@ type Schedule struct {
 	schedule scheduleDto
 }
 
-func NewSchedule(filialID string) (*Schedule, error) {
+// NewSchedule creates schedule of the filials, the single filial is used if no filial identifiers passed
+func NewSchedule(filialName string, filials ...string) (*Schedule, error) {
 	s := &Schedule{
 		schedule: scheduleDto{
-			FilialID: filialID,
-			Data:     filialScheduleMap{singleFilial: make(doctorScheduleMap, 0)},
+			FilialID: filialName,
+			Data:     make(filialScheduleMap, len(filials)),
 		},
 	}
 
+	if len(filials) == 0 {
+		filials = []string{singleFilial}
+	}
+
+	for _, filial := range filials {
+		s.schedule.Data[filialID(filial)] = make(doctorScheduleMap, 0)
+	}
+
 	return s, nil
 }
 
@@ -62,21 +76,75 @@ func (s *Schedule) ToJSON() ([]byte, error) {
 }
 
 func (s *Schedule) AddDoctorSchedule(doc *DoctorSchedule) error {
-	s.schedule.Data[singleFilial][doc.doctorID()] = doc.schedule
+	return s.AddFilialDoctorSchedule(singleFilial, doc)
+}
+
+// AddFilialDoctorSchedule adds doctor's schedule to the filial passed to NewSchedule
+func (s *Schedule) AddFilialDoctorSchedule(filial string, doc *DoctorSchedule) error {
+	doctors, ok := s.schedule.Data[filialID(filial)]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFilial, filial)
+	}
+
+	id := doc.doctorID()
+
+	if _, ok := doctors[id]; ok {
+		return fmt.Errorf("%w: %s/%s", ErrDuplicateDoctor, filial, id)
+	}
+
+	doctors[id] = doc.schedule
 
 	return nil
 }
 
+// ScheduleStats counters of the schedule to upload
+type ScheduleStats struct {
+	Doctors   int `json:"doctors"`
+	Cells     int `json:"cells"`
+	FreeCells int `json:"free_cells"`
+}
+
+func (s *Schedule) Stats() ScheduleStats {
+	var stats ScheduleStats
+
+	for _, doctors := range s.schedule.Data {
+		stats.Doctors += len(doctors)
+
+		for _, doctor := range doctors {
+			stats.Cells += len(doctor.Cells)
+
+			for _, cell := range doctor.Cells {
+				if cell.Free {
+					stats.FreeCells++
+				}
+			}
+		}
+	}
+
+	return stats
+}
+
 func (s *Schedule) IsEmpty() bool {
-	return len(s.schedule.Data[singleFilial]) == 0
+	for _, doctors := range s.schedule.Data {
+		if len(doctors) != 0 {
+			return false
+		}
+	}
+
+	return true
 }
 
 type DoctorSchedule struct {
+	id       string
+	merged   bool // keyed w/o the specialty, see NewMergedDoctorSchedule
 	schedule doctorScheduleDto
 }
 
-func NewDoctorSchedule(name string, spec string, cellsCount int) (*DoctorSchedule, error) {
+// NewDoctorSchedule creates doctor's schedule, id is an optional external doctor identifier
+// used as a stable key with the spec instead of the name of the doctor
+func NewDoctorSchedule(id string, name string, spec string, cellsCount int) (*DoctorSchedule, error) {
 	d := &DoctorSchedule{
+		id: id,
 		schedule: doctorScheduleDto{
 			Name:  name,
 			Spec:  spec,
@@ -87,19 +155,51 @@ func NewDoctorSchedule(name string, spec string, cellsCount int) (*DoctorSchedul
 	return d, nil
 }
 
+// NewMergedDoctorSchedule creates schedule of a doctor with several specialties, the doctor is keyed
+// by the external identifier or by the name only; the API has a single specialty of the doctor
+// and no specialty of time cells, so spec is the primary specialty
+func NewMergedDoctorSchedule(id string, name string, spec string, cellsCount int) (*DoctorSchedule, error) {
+	d, err := NewDoctorSchedule(id, name, spec, cellsCount)
+	if err != nil {
+		return nil, err
+	}
+
+	d.merged = true
+
+	return d, nil
+}
+
+// doctorID returns the key of the doctor, a doctor with several specialties has an entry per specialty
+// unless the schedule is merged
 func (d *DoctorSchedule) doctorID() doctorID {
-	return doctorID(fmt.Sprintf("%s/%s", d.schedule.Spec, strings.ReplaceAll(d.schedule.Name, " ", "")))
+	key := d.id
+	if key == "" {
+		key = strings.ReplaceAll(d.schedule.Name, " ", "")
+	}
+
+	if d.merged {
+		return doctorID(key)
+	}
+
+	return doctorID(fmt.Sprintf("%s/%s", d.schedule.Spec, key))
 }
 
 func (d *DoctorSchedule) AddTimeCell(startTime time.Time, duration time.Duration, free bool, room string) error {
+	endTime := startTime.Add(duration)
+
 	cell := timeCellDto{
 		Date:      startTime.Format("2006-01-02"),
 		TimeStart: startTime.Format("15:04"),
-		TimeEnd:   startTime.Add(duration).Format("15:04"),
+		TimeEnd:   endTime.Format("15:04"),
 		Free:      free,
 		Room:      room,
 	}
 
+	year, month, day := startTime.Date()
+	if endTime.Equal(time.Date(year, month, day+1, 0, 0, 0, 0, startTime.Location())) {
+		cell.TimeEnd = endOfDay // ends at midnight of the next day
+	}
+
 	if cell.TimeStart >= cell.TimeEnd {
 		return ErrStartAfterEnd
 	}
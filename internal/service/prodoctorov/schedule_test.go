@@ -2,7 +2,9 @@ package prodoctorov_test
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,7 +26,7 @@ func TestNewSchedule(t *testing.T) {
 		t.Fatalf("NewSchedule() error = %v", err)
 	}
 
-	doctorSchedule, err := prodoctorov.NewDoctorSchedule("Иванов И.И.", "Аллерголог", 1)
+	doctorSchedule, err := prodoctorov.NewDoctorSchedule("", "Иванов И.И.", "Аллерголог", 1)
 	if err != nil {
 		t.Fatalf("NewDoctorSchedule() error = %v", err)
 	}
@@ -49,6 +51,11 @@ func TestNewSchedule(t *testing.T) {
 		t.Fatalf("AddDoctorSchedule() error = %v", err)
 	}
 
+	wantStats := prodoctorov.ScheduleStats{Doctors: 1, Cells: 1, FreeCells: 1}
+	if gotStats := filialSchedule.Stats(); gotStats != wantStats {
+		t.Errorf("Stats() got = %v, want %v", gotStats, wantStats)
+	}
+
 	gotMessage, err := filialSchedule.ToJSON()
 	if err != nil {
 		t.Fatalf("ToJSON() error = %v", err)
@@ -60,3 +67,194 @@ func TestNewSchedule(t *testing.T) {
 		t.Errorf("got = %s, want %s", gotMessage, wantMessage)
 	}
 }
+
+func TestSchedule_ExternalDoctorID(t *testing.T) {
+	filialSchedule, err := prodoctorov.NewSchedule("Филиал 1")
+	if err != nil {
+		t.Fatalf("NewSchedule() error = %v", err)
+	}
+
+	for _, name := range []string{"Иванов И.И.", "Иванов И.А."} {
+		doctorSchedule, err := prodoctorov.NewDoctorSchedule("B7F2C1", name, "Аллерголог", 0)
+		if err != nil {
+			t.Fatalf("NewDoctorSchedule() error = %v", err)
+		}
+
+		err = filialSchedule.AddDoctorSchedule(doctorSchedule)
+		if name == "Иванов И.И." && err != nil {
+			t.Fatalf("AddDoctorSchedule() error = %v", err)
+		}
+
+		if name == "Иванов И.А." && !errors.Is(err, prodoctorov.ErrDuplicateDoctor) {
+			t.Fatalf("AddDoctorSchedule() error = %v, want %v", err, prodoctorov.ErrDuplicateDoctor)
+		}
+	}
+
+	gotMessage, err := filialSchedule.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+
+	wantMessage := `{"schedule":{"filial_id":"Филиал 1","data":{"filial_id":{"Аллерголог/B7F2C1":{"efio":"Иванов И.И.","espec":"Аллерголог","cells":[]}}}}}` //nolint:revive // test data
+
+	if !reflect.DeepEqual(jsonUnmarshal(t, string(gotMessage)), jsonUnmarshal(t, wantMessage)) {
+		t.Errorf("got = %s, want %s", gotMessage, wantMessage)
+	}
+}
+
+func TestSchedule_ExternalDoctorIDSpecialties(t *testing.T) {
+	filialSchedule, err := prodoctorov.NewSchedule("Филиал 1")
+	if err != nil {
+		t.Fatalf("NewSchedule() error = %v", err)
+	}
+
+	for _, spec := range []string{"Терапевт", "Гастроэнтеролог"} {
+		doctorSchedule, err := prodoctorov.NewDoctorSchedule("B7F2C1", "Иванов И.И.", spec, 0)
+		if err != nil {
+			t.Fatalf("NewDoctorSchedule() error = %v", err)
+		}
+
+		if err := filialSchedule.AddDoctorSchedule(doctorSchedule); err != nil {
+			t.Fatalf("AddDoctorSchedule() error = %v", err)
+		}
+	}
+
+	if gotStats := filialSchedule.Stats(); gotStats.Doctors != 2 {
+		t.Errorf("Stats() got = %+v, want 2 doctors", gotStats)
+	}
+}
+
+func TestSchedule_Filials(t *testing.T) {
+	filialSchedule, err := prodoctorov.NewSchedule("Клиника", "north", "south")
+	if err != nil {
+		t.Fatalf("NewSchedule() error = %v", err)
+	}
+
+	for _, filial := range []string{"north", "south", "west"} {
+		doctorSchedule, err := prodoctorov.NewDoctorSchedule("B7F2C1", "Иванов И.И.", "Аллерголог", 0)
+		if err != nil {
+			t.Fatalf("NewDoctorSchedule() error = %v", err)
+		}
+
+		err = filialSchedule.AddFilialDoctorSchedule(filial, doctorSchedule)
+		if filial != "west" && err != nil {
+			t.Fatalf("AddFilialDoctorSchedule() error = %v", err)
+		}
+
+		if filial == "west" && !errors.Is(err, prodoctorov.ErrUnknownFilial) {
+			t.Fatalf("AddFilialDoctorSchedule() error = %v, want %v", err, prodoctorov.ErrUnknownFilial)
+		}
+	}
+
+	if gotStats := filialSchedule.Stats(); gotStats.Doctors != 2 {
+		t.Errorf("Stats() got = %+v, want 2 doctors", gotStats)
+	}
+
+	gotMessage, err := filialSchedule.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+
+	wantMessage := `{"schedule":{"filial_id":"Клиника","data":{` +
+		`"north":{"Аллерголог/B7F2C1":{"efio":"Иванов И.И.","espec":"Аллерголог","cells":[]}},` +
+		`"south":{"Аллерголог/B7F2C1":{"efio":"Иванов И.И.","espec":"Аллерголог","cells":[]}}}}}`
+
+	if !reflect.DeepEqual(jsonUnmarshal(t, string(gotMessage)), jsonUnmarshal(t, wantMessage)) {
+		t.Errorf("got = %s, want %s", gotMessage, wantMessage)
+	}
+}
+
+func TestDoctorSchedule_AddTimeCell(t *testing.T) {
+	tests := []struct {
+		name      string
+		startTime time.Time
+		duration  time.Duration
+		wantEnd   string
+		wantErr   error
+	}{
+		{
+			name:      "ends at midnight",
+			startTime: time.Date(2021, 7, 1, 23, 40, 0, 0, time.UTC),
+			duration:  20 * time.Minute,
+			wantEnd:   "23:59",
+		},
+		{
+			name:      "crosses midnight",
+			startTime: time.Date(2021, 7, 1, 23, 40, 0, 0, time.UTC),
+			duration:  40 * time.Minute,
+			wantErr:   prodoctorov.ErrStartAfterEnd,
+		},
+		{
+			name:      "whole day",
+			startTime: time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+			duration:  24 * time.Hour,
+			wantEnd:   "23:59",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			doctorSchedule, err := prodoctorov.NewDoctorSchedule("", "Иванов И.И.", "Аллерголог", 1)
+			if err != nil {
+				t.Fatalf("NewDoctorSchedule() error = %v", err)
+			}
+
+			err = doctorSchedule.AddTimeCell(tt.startTime, tt.duration, true, "42")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddTimeCell() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			schedule, err := prodoctorov.NewSchedule("Филиал 1")
+			if err != nil {
+				t.Fatalf("NewSchedule() error = %v", err)
+			}
+
+			if err := schedule.AddDoctorSchedule(doctorSchedule); err != nil {
+				t.Fatalf("AddDoctorSchedule() error = %v", err)
+			}
+
+			gotMessage, err := schedule.ToJSON()
+			if err != nil {
+				t.Fatalf("ToJSON() error = %v", err)
+			}
+
+			if want := `"time_end":"` + tt.wantEnd + `"`; !strings.Contains(string(gotMessage), want) {
+				t.Errorf("got = %s, want %s", gotMessage, want)
+			}
+		})
+	}
+}
+
+func TestSchedule_MergedDoctor(t *testing.T) {
+	filialSchedule, err := prodoctorov.NewSchedule("Филиал 1")
+	if err != nil {
+		t.Fatalf("NewSchedule() error = %v", err)
+	}
+
+	doctorSchedule, err := prodoctorov.NewMergedDoctorSchedule("", "Иванов И.И.", "Гастроэнтеролог", 0)
+	if err != nil {
+		t.Fatalf("NewMergedDoctorSchedule() error = %v", err)
+	}
+
+	if err := filialSchedule.AddDoctorSchedule(doctorSchedule); err != nil {
+		t.Fatalf("AddDoctorSchedule() error = %v", err)
+	}
+
+	gotMessage, err := filialSchedule.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+
+	wantMessage := `{"schedule":{"filial_id":"Филиал 1","data":{"filial_id":{` +
+		`"ИвановИ.И.":{"efio":"Иванов И.И.","espec":"Гастроэнтеролог","cells":[]}}}}}`
+
+	if !reflect.DeepEqual(jsonUnmarshal(t, string(gotMessage)), jsonUnmarshal(t, wantMessage)) {
+		t.Errorf("got = %s, want %s", gotMessage, wantMessage)
+	}
+}
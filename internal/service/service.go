@@ -8,6 +8,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"prodoctorov/internal/service/calendar"
 	"prodoctorov/internal/service/logger"
 )
 
@@ -20,21 +21,37 @@ const (
 type Service struct {
 	config *Config
 	log    *zap.SugaredLogger
+
+	allowMassDeletion bool // applies until the first upload session reaches the guard
 }
 
-func NewService(configFile string) (*Service, error) {
+// NewService creates the service, allowMassDeletion overrides the mass-deletion guard for the first upload
+// session reaching the guard
+func NewService(configFile string, allowMassDeletion bool) (*Service, error) {
 	cfg, err := LoadConfig(configFile)
 	if err != nil {
 		return nil, err
 	}
 
 	s := Service{
-		config: cfg,
+		config:            cfg,
+		allowMassDeletion: allowMassDeletion,
 	}
 
 	return &s, nil
 }
 
+// AddClosure appends the ad-hoc closure to the calendar file of the config, running service picks it up
+// in the next upload session
+func AddClosure(configFile string, closure *calendar.Closure) error {
+	cfg, err := LoadConfig(configFile)
+	if err != nil {
+		return err
+	}
+
+	return calendar.AddClosure(&cfg.Calendar, closure)
+}
+
 func (s *Service) Run(closeChan chan os.Signal) error {
 	var err error
 
@@ -58,7 +75,7 @@ func (s *Service) Run(closeChan chan os.Signal) error {
 
 			return nil
 		case <-ticker.C:
-			session, err := NewUploadSession(s.config, s.log)
+			session, err := NewUploadSession(s.config, s.log, s.allowMassDeletion)
 			if err != nil {
 				return err // fatal error
 			}
@@ -67,6 +84,10 @@ func (s *Service) Run(closeChan chan os.Signal) error {
 				s.log.Error(err)
 			}
 
+			if session.IsGuardChecked() {
+				s.allowMassDeletion = false
+			}
+
 			ticker.Reset(s.config.startEvery) // rearm timer after upload
 		}
 	}
@@ -0,0 +1,107 @@
+package specialties
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
+)
+
+// policies of specialties w/o mapping
+const (
+	UnmappedWarn = "warn" // Domino specialty is published as is and reported
+	UnmappedDrop = "drop" // doctor's schedule is not published
+	UnmappedFail = "fail" // schedule is not uploaded at all
+
+	DefaultUnmapped = UnmappedWarn
+)
+
+var (
+	ErrBadUnmapped = errors.New("policy must be one of warn, drop, fail (specialties.unmapped option)")
+	ErrNoEspec     = errors.New("prodoctorov specialty is empty (espec option)")
+	ErrNoMatch     = errors.New("rule has no match nor regex (match, regex options)")
+)
+
+// Rule maps Domino specialties matched exactly (case insensitive) or by regular expressions to prodoctorov one
+type Rule struct {
+	Espec string   `yaml:"espec"`
+	Match []string `yaml:"match"`
+	Regex []string `yaml:"regex"`
+
+	regex []*regexp.Regexp
+}
+
+// Config specialty dictionary, rules are read from the config and from the optional side file,
+// if no rules are configured specialties are published as is
+type Config struct {
+	Rules    []*Rule `yaml:"rules"`
+	File     string  `yaml:"file"` // YAML list of rules
+	Unmapped string  `yaml:"unmapped"`
+
+	rules []*Rule // rules of the config followed by the rules of the side file
+	exact map[string]string
+}
+
+func (c *Config) Check() error {
+	if c.Unmapped == "" {
+		c.Unmapped = DefaultUnmapped
+	}
+
+	switch c.Unmapped {
+	case UnmappedWarn, UnmappedDrop, UnmappedFail:
+	default:
+		return fmt.Errorf("%w: %s", ErrBadUnmapped, c.Unmapped)
+	}
+
+	c.rules = append(make([]*Rule, 0, len(c.Rules)), c.Rules...)
+
+	if c.File != "" {
+		data, err := ioutil.ReadFile(filepath.Clean(c.File))
+		if err != nil {
+			return err
+		}
+
+		rules := make([]*Rule, 0)
+		if err := yaml.Unmarshal(data, &rules); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", c.File, err)
+		}
+
+		c.rules = append(c.rules, rules...)
+	}
+
+	c.exact = make(map[string]string)
+
+	for _, rule := range c.rules {
+		if rule.Espec == "" {
+			return fmt.Errorf("%w: %v", ErrNoEspec, rule.Match)
+		}
+
+		if len(rule.Match) == 0 && len(rule.Regex) == 0 {
+			return fmt.Errorf("%w: %s", ErrNoMatch, rule.Espec)
+		}
+
+		for _, spec := range rule.Match {
+			c.exact[normalize(spec)] = rule.Espec
+		}
+
+		rule.regex = make([]*regexp.Regexp, 0, len(rule.Regex))
+
+		for _, expr := range rule.Regex {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("bad regex of %s: %w", rule.Espec, err)
+			}
+
+			rule.regex = append(rule.regex, re)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) isEnabled() bool {
+	return len(c.rules) != 0
+}
@@ -0,0 +1,84 @@
+package specialties
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"prodoctorov/internal/service/domino"
+)
+
+var (
+	ErrUnmapped = errors.New("specialties w/o mapping to prodoctorov")
+)
+
+func normalize(spec string) string {
+	return strings.ToLower(strings.Join(strings.Fields(spec), " "))
+}
+
+// Espec returns prodoctorov specialty of the Domino one, exact matches are preferred to regular expressions
+func (c *Config) Espec(spec string) (string, bool) {
+	if espec, ok := c.exact[normalize(spec)]; ok {
+		return espec, true
+	}
+
+	for _, rule := range c.rules {
+		for _, re := range rule.regex {
+			if re.MatchString(spec) {
+				return rule.Espec, true
+			}
+		}
+	}
+
+	return spec, false
+}
+
+// Key returns prodoctorov specialty of the Domino one or the normalized Domino specialty w/o mapping,
+// specialties of different sources are compared by the key
+func (c *Config) Key(spec string) string {
+	if espec, ok := c.Espec(spec); ok {
+		return espec
+	}
+
+	return normalize(spec)
+}
+
+// Apply replaces specialties of the records by prodoctorov ones, returns the records left
+// and sorted specialties w/o mapping; error if unmapped specialty found and the policy is "fail"
+func Apply(config *Config, records domino.Records) (domino.Records, []string, error) {
+	if !config.isEnabled() {
+		return records, []string{}, nil
+	}
+
+	unmapped := make(map[string]struct{})
+	result := make(domino.Records, 0, len(records))
+
+	for _, r := range records {
+		espec, ok := config.Espec(r.Spec)
+		if !ok {
+			unmapped[r.Spec] = struct{}{}
+
+			if config.Unmapped == UnmappedDrop {
+				continue
+			}
+		}
+
+		r.KeepDominoValues()
+		r.Spec = espec
+		result = append(result, r)
+	}
+
+	specs := make([]string, 0, len(unmapped))
+	for spec := range unmapped {
+		specs = append(specs, spec)
+	}
+
+	sort.Strings(specs)
+
+	if len(specs) != 0 && config.Unmapped == UnmappedFail {
+		return nil, specs, fmt.Errorf("%w: %s", ErrUnmapped, strings.Join(specs, ", "))
+	}
+
+	return result, specs, nil
+}
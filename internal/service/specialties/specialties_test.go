@@ -0,0 +1,159 @@
+package specialties_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/specialties"
+)
+
+func TestApply(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "specialties.yaml")
+
+	rules := []byte(`
+- espec: Терапевт
+  match: ["Терапевт", "Терапевт участковый"]
+`)
+	if err := ioutil.WriteFile(rulesFile, rules, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	newConfig := func(unmapped string) specialties.Config {
+		return specialties.Config{
+			Rules: []*specialties.Rule{
+				{Espec: "Оториноларинголог", Match: []string{"лор"}, Regex: []string{`^Оториноларинголог`}},
+			},
+			File:     rulesFile,
+			Unmapped: unmapped,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		config       specialties.Config
+		want         []string
+		wantUnmapped []string
+		wantErr      error
+	}{
+		{
+			name:         "disabled",
+			config:       specialties.Config{},
+			want:         []string{"ЛОР", "Оториноларинголог (взр.)", "Терапевт  участковый", "Массажист"},
+			wantUnmapped: []string{},
+		},
+		{
+			name:         "warn",
+			config:       newConfig(specialties.UnmappedWarn),
+			want:         []string{"Оториноларинголог", "Оториноларинголог", "Терапевт", "Массажист"},
+			wantUnmapped: []string{"Массажист"},
+		},
+		{
+			name:         "drop",
+			config:       newConfig(specialties.UnmappedDrop),
+			want:         []string{"Оториноларинголог", "Оториноларинголог", "Терапевт"},
+			wantUnmapped: []string{"Массажист"},
+		},
+		{
+			name:         "fail",
+			config:       newConfig(specialties.UnmappedFail),
+			want:         []string{},
+			wantUnmapped: []string{"Массажист"},
+			wantErr:      specialties.ErrUnmapped,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			records := domino.Records{
+				{Spec: "ЛОР", Name: "Иванов Л.Б."},
+				{Spec: "Оториноларинголог (взр.)", Name: "Иванов Л.Б."},
+				{Spec: "Терапевт  участковый", Name: "Петров Е.Е."},
+				{Spec: "Массажист", Name: "Иванов И.Н."},
+			}
+
+			result, gotUnmapped, err := specialties.Apply(&tt.config, records)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := make([]string, 0, len(result))
+			for _, r := range result {
+				got = append(got, r.Spec)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() got specialties = %q, want %q", got, tt.want)
+			}
+
+			if !reflect.DeepEqual(gotUnmapped, tt.wantUnmapped) {
+				t.Errorf("Apply() got unmapped = %q, want %q", gotUnmapped, tt.wantUnmapped)
+			}
+		})
+	}
+}
+
+func TestConfig_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  specialties.Config
+		wantErr error
+	}{
+		{name: "bad policy", config: specialties.Config{Unmapped: "keep"}, wantErr: specialties.ErrBadUnmapped},
+		{
+			name:    "no espec",
+			config:  specialties.Config{Rules: []*specialties.Rule{{Match: []string{"ЛОР"}}}},
+			wantErr: specialties.ErrNoEspec,
+		},
+		{
+			name:    "no match",
+			config:  specialties.Config{Rules: []*specialties.Rule{{Espec: "Оториноларинголог"}}},
+			wantErr: specialties.ErrNoMatch,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Check(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_CheckTwice(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "specialties.yaml")
+	if err := ioutil.WriteFile(rulesFile, []byte(`[{espec: Терапевт, match: ["Терапевт"]}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := specialties.Config{
+		Rules: []*specialties.Rule{{Espec: "Оториноларинголог", Match: []string{"лор"}}},
+		File:  rulesFile,
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := config.Check(); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+
+	if len(config.Rules) != 1 {
+		t.Errorf("Check() got rules = %d, want 1", len(config.Rules))
+	}
+
+	if espec, ok := config.Espec("терапевт"); !ok || espec != "Терапевт" {
+		t.Errorf("Espec() got = %s, %v", espec, ok)
+	}
+}
@@ -0,0 +1,111 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaults
+const (
+	DefaultHorizon = 14 * 24 * time.Hour
+
+	ClockLayout = "15:04"
+)
+
+var (
+	ErrNoDoctor   = errors.New("template must have spec and name (templates.doctors option)")
+	ErrBadWeekday = errors.New("weekday must be an English day name, e.g. monday (templates.doctors.slots option)")
+	ErrBadSlot    = errors.New("slot must have start before end and positive slot length (templates.doctors.slots option)")
+	ErrBadHorizon = errors.New("horizon must not be negative (templates.horizon option)")
+)
+
+// Slot recurring weekly time range split into cells of the slot length
+type Slot struct {
+	Weekday string        `yaml:"weekday"`
+	Start   string        `yaml:"start"` // wall clock, e.g. 09:00
+	End     string        `yaml:"end"`
+	Slot    time.Duration `yaml:"slot"`
+	Room    string        `yaml:"room"`
+
+	weekday time.Weekday
+	start   time.Duration // since midnight
+	end     time.Duration
+}
+
+// Template weekly schedule of the doctor, the doctor is identified by the external identifier
+// or the name regardless of spelling variants
+type Template struct {
+	Spec     string  `yaml:"spec"`
+	Name     string  `yaml:"name"`
+	DoctorID string  `yaml:"doctor_id"`
+	Slots    []*Slot `yaml:"slots"`
+}
+
+// Config of weekly templates, cells are generated for the days w/o Domino records of the doctor
+type Config struct {
+	Horizon time.Duration `yaml:"horizon"` // from the current time, by default domino.horizon.future or 14 days
+	Doctors []*Template   `yaml:"doctors"`
+}
+
+func (c *Config) Check() error {
+	if c.Horizon < 0 {
+		return ErrBadHorizon
+	}
+
+	for _, template := range c.Doctors {
+		if template.Spec == "" || template.Name == "" {
+			return fmt.Errorf("%w: %s/%s", ErrNoDoctor, template.Spec, template.Name)
+		}
+
+		for _, slot := range template.Slots {
+			if err := slot.check(); err != nil {
+				return fmt.Errorf("%w: %s/%s", err, template.Spec, template.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), strings.TrimSpace(name)) {
+			return day, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrBadWeekday, name)
+}
+
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse(ClockLayout, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (s *Slot) check() error {
+	var err error
+
+	if s.weekday, err = parseWeekday(s.Weekday); err != nil {
+		return err
+	}
+
+	if s.start, err = parseClock(s.Start); err != nil {
+		return fmt.Errorf("%w: %v", ErrBadSlot, err)
+	}
+
+	if s.end, err = parseClock(s.End); err != nil {
+		return fmt.Errorf("%w: %v", ErrBadSlot, err)
+	}
+
+	if s.end <= s.start || s.Slot <= 0 {
+		return fmt.Errorf("%w: %s %s-%s %v", ErrBadSlot, s.Weekday, s.Start, s.End, s.Slot)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package templates
+
+import (
+	"fmt"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/names"
+)
+
+func (t *Template) record() *domino.Record {
+	return &domino.Record{Spec: t.Spec, Name: t.Name, DoctorID: t.DoctorID, Generated: true}
+}
+
+// busyDays days of doctors by the external identifier and by the normalized name
+type busyDays map[string]struct{}
+
+func dayKey(doctor string, date time.Time) string {
+	return doctor + " " + date.Format("2006-01-02")
+}
+
+func (b busyDays) add(day domino.DoctorDay) {
+	if day.DoctorID != "" {
+		b[dayKey("id:"+day.DoctorID, day.Date)] = struct{}{}
+	}
+
+	b[dayKey(names.Normalize(day.Name), day.Date)] = struct{}{}
+}
+
+func (b busyDays) isBusy(t *Template, date time.Time) bool {
+	if t.DoctorID != "" {
+		if _, ok := b[dayKey("id:"+t.DoctorID, date)]; ok {
+			return true
+		}
+	}
+
+	_, ok := b[dayKey(names.Normalize(t.Name), date)]
+
+	return ok
+}
+
+// Generate adds free records by the templates for the days within the horizon w/o records of the doctor
+// in any specialty, days of the export rows dropped or rejected on import count as well; generated records
+// are marked and limited by the Domino horizon. Returns the records and the generated days as "doctor date"
+// strings
+func Generate(
+	config *Config,
+	horizon *domino.Horizon,
+	records domino.Records,
+	seen domino.DoctorDays,
+	timeNow time.Time,
+) (domino.Records, []string) {
+	generated := make([]string, 0)
+
+	if len(config.Doctors) == 0 {
+		return records, generated
+	}
+
+	now := domino.WallClock(timeNow).Truncate(time.Second)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	until := now.Add(config.horizon(horizon))
+
+	days := make(domino.DoctorDays, len(seen))
+	for day := range seen {
+		days[day] = struct{}{}
+	}
+
+	for _, r := range records {
+		days.Add(r)
+	}
+
+	busy := make(busyDays, len(days))
+	for day := range days {
+		busy.add(day)
+	}
+
+	for _, template := range config.Doctors {
+		id := template.record().ID()
+
+		for day := today; day.Before(until); day = day.AddDate(0, 0, 1) {
+			if busy.isBusy(template, day) {
+				continue
+			}
+
+			cells := template.cells(day, now, until, horizon)
+			if len(cells) == 0 {
+				continue
+			}
+
+			records = append(records, cells...)
+			generated = append(generated, fmt.Sprintf("%s: %d cells", dayKey(id, day), len(cells)))
+		}
+	}
+
+	return records, generated
+}
+
+// horizon returns the horizon of the templates, domino.horizon.future is used if not set
+func (c *Config) horizon(dominoHorizon *domino.Horizon) time.Duration {
+	switch {
+	case c.Horizon != 0:
+		return c.Horizon
+	case dominoHorizon.Future != 0:
+		return dominoHorizon.Future
+	default:
+		return DefaultHorizon
+	}
+}
+
+// cells returns free records of the day by the template, cells out of [now, until) or the Domino horizon
+// are skipped
+func (t *Template) cells(day time.Time, now time.Time, until time.Time, horizon *domino.Horizon) domino.Records {
+	result := make(domino.Records, 0)
+
+	for _, slot := range t.Slots {
+		if slot.weekday != day.Weekday() {
+			continue
+		}
+
+		for start := slot.start; start+slot.Slot <= slot.end; start += slot.Slot {
+			startTime := day.Add(start)
+			if startTime.Before(now) || !startTime.Before(until) || !horizon.Contains(now, startTime) {
+				continue
+			}
+
+			r := t.record()
+			r.StartTime = startTime
+			r.Duration = slot.Slot
+			r.Free = true
+			r.Room = slot.Room
+
+			result = append(result, r)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,170 @@
+package templates_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/templates"
+)
+
+func TestGenerate(t *testing.T) {
+	day := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2021, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	newConfig := func(horizon time.Duration) *templates.Config {
+		return &templates.Config{
+			Horizon: horizon,
+			Doctors: []*templates.Template{{
+				Spec:     "Невролог",
+				Name:     "Козлов К.К.",
+				DoctorID: "1001",
+				Slots: []*templates.Slot{
+					{Weekday: "Monday", Start: "09:00", End: "11:00", Slot: 30 * time.Minute, Room: "3 кабинет"},
+					{Weekday: "wednesday", Start: "14:00", End: "15:00", Slot: 20 * time.Minute, Room: "3 кабинет"},
+				},
+			}},
+		}
+	}
+
+	vacation := make(domino.DoctorDays)
+	vacation.Add(&domino.Record{Spec: "Невролог", Name: "Козлов К.К.", StartTime: day(11, 8, 9, 0)})
+
+	tests := []struct {
+		name     string
+		config   *templates.Config
+		horizon  domino.Horizon
+		records  domino.Records
+		seen     domino.DoctorDays
+		want     []time.Time
+		wantDays []string
+	}{
+		{
+			name:   "name variant",
+			config: newConfig(7 * 24 * time.Hour),
+			records: domino.Records{
+				{Spec: "Невролог (взр.)", Name: "Козлов  К. К.", StartTime: day(11, 3, 16, 0), Duration: 20 * time.Minute},
+			},
+			want: []time.Time{
+				day(11, 3, 16, 0), day(11, 1, 10, 30), day(11, 8, 9, 0), day(11, 8, 9, 30), day(11, 8, 10, 0),
+			},
+			wantDays: []string{
+				"Невролог/1001 2021-11-01: 1 cells",
+				"Невролог/1001 2021-11-08: 3 cells",
+			},
+		},
+		{
+			name:   "external id",
+			config: newConfig(7 * 24 * time.Hour),
+			records: domino.Records{
+				{Spec: "Невролог", Name: "Козлов К.", DoctorID: "1001", StartTime: day(11, 8, 16, 0)},
+			},
+			want: []time.Time{
+				day(11, 8, 16, 0), day(11, 1, 10, 30), day(11, 3, 14, 0), day(11, 3, 14, 20), day(11, 3, 14, 40),
+			},
+			wantDays: []string{
+				"Невролог/1001 2021-11-01: 1 cells",
+				"Невролог/1001 2021-11-03: 3 cells",
+			},
+		},
+		{
+			name:    "vacation day",
+			config:  newConfig(7 * 24 * time.Hour),
+			records: domino.Records{},
+			seen:    vacation,
+			want:    []time.Time{day(11, 1, 10, 30), day(11, 3, 14, 0), day(11, 3, 14, 20), day(11, 3, 14, 40)},
+			wantDays: []string{
+				"Невролог/1001 2021-11-01: 1 cells",
+				"Невролог/1001 2021-11-03: 3 cells",
+			},
+		},
+		{
+			name:     "domino horizon",
+			config:   newConfig(0),
+			horizon:  domino.Horizon{Future: 52 * time.Hour},
+			records:  domino.Records{},
+			want:     []time.Time{day(11, 1, 10, 30), day(11, 3, 14, 0), day(11, 3, 14, 20)},
+			wantDays: []string{"Невролог/1001 2021-11-01: 1 cells", "Невролог/1001 2021-11-03: 2 cells"},
+		},
+		{
+			name:     "shorter domino horizon",
+			config:   newConfig(7 * 24 * time.Hour),
+			horizon:  domino.Horizon{Future: 24 * time.Hour},
+			records:  domino.Records{},
+			want:     []time.Time{day(11, 1, 10, 30)},
+			wantDays: []string{"Невролог/1001 2021-11-01: 1 cells"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Check(); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			got, gotDays := templates.Generate(tt.config, &tt.horizon, tt.records, tt.seen, day(11, 1, 10, 30))
+
+			if !reflect.DeepEqual(gotDays, tt.wantDays) {
+				t.Errorf("Generate() got days = %v, want %v", gotDays, tt.wantDays)
+			}
+
+			gotStarts := make([]time.Time, 0, len(got))
+			for _, r := range got {
+				gotStarts = append(gotStarts, r.StartTime)
+			}
+
+			if !reflect.DeepEqual(gotStarts, tt.want) {
+				t.Errorf("Generate() got = %v, want %v", gotStarts, tt.want)
+			}
+
+			for _, r := range got[len(tt.records):] {
+				if !r.Free || !r.Generated || r.Room != "3 кабинет" {
+					t.Errorf("Generate() got record = %+v", r)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		slot    templates.Slot
+		wantErr error
+	}{
+		{
+			name:    "bad weekday",
+			slot:    templates.Slot{Weekday: "пн", Start: "09:00", End: "11:00", Slot: time.Hour},
+			wantErr: templates.ErrBadWeekday,
+		},
+		{
+			name:    "end before start",
+			slot:    templates.Slot{Weekday: "monday", Start: "11:00", End: "09:00", Slot: time.Hour},
+			wantErr: templates.ErrBadSlot,
+		},
+		{
+			name:    "no slot length",
+			slot:    templates.Slot{Weekday: "monday", Start: "09:00", End: "11:00"},
+			wantErr: templates.ErrBadSlot,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			config := templates.Config{
+				Doctors: []*templates.Template{{Spec: "Невролог", Name: "Козлов К.К.", Slots: []*templates.Slot{&tt.slot}}},
+			}
+
+			if err := config.Check(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
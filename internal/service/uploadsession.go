@@ -3,30 +3,87 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 
+	"prodoctorov/internal/service/calendar"
 	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/filials"
+	"prodoctorov/internal/service/filter"
+	"prodoctorov/internal/service/guard"
+	"prodoctorov/internal/service/hours"
+	"prodoctorov/internal/service/manual"
+	"prodoctorov/internal/service/names"
+	"prodoctorov/internal/service/overrides"
 	"prodoctorov/internal/service/prodoctorov"
+	"prodoctorov/internal/service/specialties"
+	"prodoctorov/internal/service/templates"
 )
 
 type CsvRecords [][]string
 
+// SessionResult counters of a single upload session
+type SessionResult struct {
+	Total         int `json:"total"`
+	Imported      int `json:"imported"`
+	DroppedPast   int `json:"dropped_past"`
+	DroppedFuture int `json:"dropped_future"`
+	DroppedStatus int `json:"dropped_status"`
+	Rejected      int `json:"rejected"`
+
+	UnknownStatuses map[string]int `json:"unknown_statuses,omitempty"`
+
+	Manual         int `json:"manual,omitempty"`          // records of the manual schedule
+	ManualRejected int `json:"manual_rejected,omitempty"` // malformed rows of the manual schedule
+
+	TemplateDays []string `json:"template_days,omitempty"` // doctors' days generated by weekly templates
+
+	Filtered   int         `json:"filtered"`              // records dropped by the filter
+	FilterHits filter.Hits `json:"filter_hits,omitempty"` // rule name -> matched records
+
+	Schedule       prodoctorov.ScheduleStats `json:"schedule"`
+	ScheduleReport ScheduleReport            `json:"schedule_report"`
+}
+
+// ScheduleReport findings of the schedule conversion worth fixing in HIS or in dictionaries
+type ScheduleReport struct {
+	NoFilial            []string `json:"no_filial,omitempty"`            // doctors' schedules w/o filial, skipped
+	UnmappedNames       []string `json:"unmapped_names,omitempty"`       // doctor names w/o alias
+	UnmappedSpecialties []string `json:"unmapped_specialties,omitempty"` // Domino specialties w/o mapping
+	FullyBusy           []string `json:"fully_busy,omitempty"`           // doctors w/o free cells, not published
+	Closed              int      `json:"closed,omitempty"`               // records of closed days, see calendar
+	Overrides           []string `json:"overrides,omitempty"`            // active doctor overrides
+	Overridden          int      `json:"overridden,omitempty"`           // records of overridden doctors
+	UnmatchedOverrides  []string `json:"unmatched_overrides,omitempty"`  // active overrides w/o records
+	Generated           int      `json:"generated,omitempty"`            // records of weekly templates scheduled
+	Merged              []string `json:"merged,omitempty"`               // doctors with specialties merged
+	MergedByName        []string `json:"merged_by_name,omitempty"`       // merged w/o external identifier
+
+	// OutOfHours time cells outside working hours, they go to the validation report as well
+	OutOfHours []*domino.OutOfHoursCell `json:"out_of_hours,omitempty"`
+}
+
 type UploadSession struct {
 	config    *Config
 	sessionID string
+	result    SessionResult
+
+	allowMassDeletion bool // operator's override of the mass-deletion guard
+	guardChecked      bool // the schedule reached the guard, so the override is used up
 
 	log *zap.SugaredLogger
 }
 
-func NewUploadSession(config *Config, parentLogger *zap.SugaredLogger) (*UploadSession, error) {
+func NewUploadSession(config *Config, parentLogger *zap.SugaredLogger, allowMassDeletion bool) (*UploadSession, error) {
 	sessionID := sessionID()
 
 	e := &UploadSession{
-		config:    config,
-		sessionID: sessionID,
-		log:       parentLogger.Named(fmt.Sprintf("UPLOAD:%s", sessionID)),
+		config:            config,
+		sessionID:         sessionID,
+		allowMassDeletion: allowMassDeletion,
+		log:               parentLogger.Named(fmt.Sprintf("UPLOAD:%s", sessionID)),
 	}
 
 	return e, nil
@@ -36,10 +93,17 @@ func sessionID() string {
 	return time.Now().Format("20060102T150405.999999999")
 }
 
+// Result returns counters of the session, filled while Upload is running
+func (s *UploadSession) Result() SessionResult {
+	return s.result
+}
+
 func (s *UploadSession) Upload(ctx context.Context) error {
 	s.log.Info("Start schedule upload")
 
-	defer s.log.Info("Schedule upload done")
+	defer func() {
+		s.log.Infow("Schedule upload done", "result", s.result)
+	}()
 
 	dominoSchedule, err := domino.DownloadSchedule(
 		ctx,
@@ -53,9 +117,50 @@ func (s *UploadSession) Upload(ctx context.Context) error {
 		return err
 	}
 
-	schedule, err := CreateSchedule(
-		s.config.Prodoctorov.FilialName,
-		dominoSchedule.Schedule(),
+	importReport := dominoSchedule.Report()
+	s.result.Total = importReport.Total
+	s.result.Imported = importReport.Imported
+	s.result.DroppedPast = importReport.DroppedPast
+	s.result.DroppedFuture = importReport.DroppedFuture
+	s.result.DroppedStatus = importReport.DroppedStatus
+	s.result.Rejected = importReport.RejectedCount()
+	s.result.UnknownStatuses = importReport.UnknownStatuses
+
+	if len(importReport.UnknownStatuses) != 0 {
+		s.log.Warnw("Unknown slot status codes", "codes", importReport.UnknownStatuses)
+	}
+
+	if err := importReport.CheckThreshold(&s.config.Domino.MaxRejected); err != nil {
+		return err
+	}
+
+	records, err := s.mergeManual(dominoSchedule.Schedule())
+	if err != nil {
+		return err
+	}
+
+	records, s.result.TemplateDays = templates.Generate(
+		&s.config.Templates,
+		&s.config.Domino.Horizon,
+		records,
+		importReport.DoctorDays,
+		time.Now(),
+	)
+	if len(s.result.TemplateDays) != 0 {
+		s.log.Infow("Days generated by weekly templates", "days", s.result.TemplateDays)
+	}
+
+	filtered, hits := filter.Apply(&s.config.Filter, records)
+	s.result.Filtered = len(records) - len(filtered)
+	s.result.FilterHits = hits
+
+	if len(hits) != 0 {
+		s.log.Infow("Filter rules hits", "filtered", s.result.Filtered, "hits", hits)
+	}
+
+	schedule, report, err := CreateSchedule(
+		s.config,
+		filtered,
 		func(message string) {
 			s.log.Error(message)
 		},
@@ -64,7 +169,69 @@ func (s *UploadSession) Upload(ctx context.Context) error {
 		return err
 	}
 
-	return prodoctorov.Upload(
+	s.result.ScheduleReport = *report
+
+	importReport.OutOfHours = report.OutOfHours
+	dominoSchedule.WriteReport(func(message string) {
+		s.log.Error(message)
+	})
+
+	if len(report.NoFilial) != 0 {
+		s.log.Warnw("Doctors' schedules w/o filial skipped", "doctors", report.NoFilial)
+	}
+
+	if report.Closed != 0 {
+		s.log.Infow("Records of closed days", "records", report.Closed, "action", s.config.Calendar.Action)
+	}
+
+	if len(report.OutOfHours) != 0 {
+		s.log.Warnw("Time cells outside working hours", "cells", len(report.OutOfHours),
+			"doctors", outOfHoursByDoctor(report.OutOfHours), "action", s.config.WorkingHours.Action)
+	}
+
+	if len(report.Overrides) != 0 {
+		s.log.Infow("Doctor overrides active", "overrides", report.Overrides, "records", report.Overridden)
+	}
+
+	if len(report.UnmatchedOverrides) != 0 {
+		s.log.Warnw("Doctor overrides matched no records", "overrides", report.UnmatchedOverrides)
+	}
+
+	if len(report.Merged) != 0 {
+		s.log.Infow("Doctors with specialties merged", "doctors", report.Merged)
+	}
+
+	if len(report.MergedByName) != 0 {
+		s.log.Warnw("Doctors merged by name w/o external identifier, namesakes are merged too",
+			"doctors", report.MergedByName)
+	}
+
+	if len(report.FullyBusy) != 0 {
+		s.log.Infow("Fully busy doctors not published", "doctors", report.FullyBusy)
+	}
+
+	if len(report.UnmappedNames) != 0 {
+		s.log.Warnw("Doctor names w/o alias", "names", report.UnmappedNames)
+	}
+
+	if len(report.UnmappedSpecialties) != 0 {
+		s.log.Warnw("Specialties w/o mapping", "specialties", report.UnmappedSpecialties)
+	}
+
+	s.result.Schedule = schedule.Stats()
+
+	current := &guard.Stats{
+		SessionID: s.sessionID,
+		Time:      time.Now(),
+		Doctors:   s.result.Schedule.Doctors,
+		FreeCells: s.result.Schedule.FreeCells,
+	}
+
+	if err := s.checkMassDeletion(current); err != nil {
+		return err
+	}
+
+	err = prodoctorov.Upload(
 		ctx,
 		&s.config.Prodoctorov,
 		s.sessionID,
@@ -73,38 +240,280 @@ func (s *UploadSession) Upload(ctx context.Context) error {
 		},
 		schedule,
 	)
+	if err != nil {
+		return err
+	}
+
+	if s.config.Guard.IsEnabled() && !schedule.IsEmpty() {
+		if err := guard.SaveLast(&s.config.Guard, current); err != nil {
+			return fmt.Errorf("%w: %v", guard.ErrNotSaved, err)
+		}
+	}
+
+	return nil
 }
 
-type ErrorLogger func(string)
+// checkMassDeletion compares the schedule to the last successful upload, unless the operator overrides it
+func (s *UploadSession) checkMassDeletion(current *guard.Stats) error {
+	s.guardChecked = true
+
+	if !s.config.Guard.IsEnabled() {
+		return nil
+	}
 
-func CreateSchedule(filialID string, dominoSchedule domino.Records, log ErrorLogger) (*prodoctorov.Schedule, error) {
-	schedule, err := prodoctorov.NewSchedule(filialID)
+	last, err := guard.LoadLast(&s.config.Guard)
+	if err != nil {
+		return err
+	}
+
+	err = guard.Compare(&s.config.Guard, last, current)
+	if err != nil && s.allowMassDeletion {
+		s.log.Warnf("Mass-deletion guard overridden by operator: %v", err)
+
+		return nil
+	}
+
+	return err
+}
+
+// mergeManual adds records of the manual schedule source if configured
+func (s *UploadSession) mergeManual(records domino.Records) (domino.Records, error) {
+	if !s.config.Manual.IsEnabled() {
+		return records, nil
+	}
+
+	manualRecords, report, err := manual.Load(
+		&s.config.Manual,
+		&s.config.Domino,
+		time.Now(),
+		func(message string) {
+			s.log.Errorf("manual schedule: %s", message)
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	dominoSchedule.LoadDoctorSchedule(func(export *domino.DoctorSchedule) {
-		doctorSchedule, err := prodoctorov.NewDoctorSchedule(export.Name, export.Spec, len(export.Cells))
+	s.result.Manual = report.Imported
+	s.result.ManualRejected = report.RejectedCount()
+
+	records, both := manual.Merge(&s.config.Manual, s.doctorKey, records, manualRecords)
+	if len(both) != 0 {
+		s.log.Infow("Doctors in both Domino and manual schedule", "doctors", both, "prefer", s.config.Manual.Prefer)
+	}
+
+	return records, nil
+}
+
+// doctorKey identifies the doctor regardless of spelling variants of the specialty and the name
+func (s *UploadSession) doctorKey(r *domino.Record) string {
+	key := r.DoctorID
+	if key == "" {
+		name, _ := s.config.Names.Canonical(r.Name)
+		key = names.Normalize(name)
+	}
+
+	return s.config.Specialties.Key(r.Spec) + "/" + key
+}
+
+// outOfHoursByDoctor counts time cells outside working hours by doctor for the session log,
+// the cells themselves go to the validation report
+func outOfHoursByDoctor(cells []*domino.OutOfHoursCell) map[string]int {
+	result := make(map[string]int)
+
+	for _, cell := range cells {
+		result[fmt.Sprintf("%s/%s", cell.Spec, cell.Name)]++
+	}
+
+	return result
+}
+
+// IsGuardChecked reports whether the session reached the mass-deletion guard, the operator's override
+// applies until a session does
+func (s *UploadSession) IsGuardChecked() bool {
+	return s.guardChecked
+}
+
+type ErrorLogger func(string)
+
+// CreateSchedule converts Domino records to the schedule to upload
+func CreateSchedule(
+	config *Config,
+	dominoSchedule domino.Records,
+	log ErrorLogger,
+) (*prodoctorov.Schedule, *ScheduleReport, error) {
+	schedule, err := prodoctorov.NewSchedule(config.Prodoctorov.FilialName, config.Filials.IDs...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &ScheduleReport{}
+
+	dominoSchedule, report.NoFilial = filials.Apply(&config.Filials, dominoSchedule)
+
+	closures, err := config.Calendar.Load()
+	if err != nil {
+		return nil, report, err
+	}
+
+	dominoSchedule, report.Closed = calendar.Apply(&config.Calendar, closures, dominoSchedule)
+
+	doctorOverrides, err := config.Overrides.Load()
+	if err != nil {
+		return nil, report, err
+	}
+
+	activeOverrides := doctorOverrides.Active(time.Now())
+	for _, override := range activeOverrides {
+		report.Overrides = append(report.Overrides, override.String())
+	}
+
+	var unmatched overrides.Overrides
+
+	dominoSchedule, report.Overridden, unmatched = overrides.Apply(activeOverrides, dominoSchedule)
+	for _, override := range unmatched {
+		report.UnmatchedOverrides = append(report.UnmatchedOverrides, override.String())
+	}
+
+	report.UnmappedNames = names.Apply(&config.Names, dominoSchedule)
+
+	dominoSchedule, report.UnmappedSpecialties, err = specialties.Apply(&config.Specialties, dominoSchedule)
+	if err != nil {
+		return nil, report, err
+	}
+
+	for _, r := range dominoSchedule {
+		if r.Generated {
+			report.Generated++
+		}
+	}
+
+	exports := make([]*domino.DoctorSchedule, 0)
+
+	for _, export := range dominoSchedule.GroupByDoctor(&config.Domino) {
+		if prepareDoctorSchedule(config, export, report, log) {
+			exports = append(exports, export)
+		}
+	}
+
+	if config.Domino.MergeSpecialties {
+		exports = mergeSpecialties(config, exports, report, log)
+	}
+
+	for _, export := range exports {
+		if !export.ApplyPublishPolicy(&config.Domino.Publish) {
+			report.FullyBusy = append(report.FullyBusy, fmt.Sprintf("%s/%s", export.Spec, export.Name))
+
+			continue
+		}
+
+		addDoctorSchedule(config, schedule, export, log)
+	}
+
+	return schedule, report, nil
+}
+
+// prepareDoctorSchedule applies cell level rules to the doctor's schedule, returns false if it is skipped
+func prepareDoctorSchedule(
+	config *Config,
+	export *domino.DoctorSchedule,
+	report *ScheduleReport,
+	log ErrorLogger,
+) bool {
+	conflicts, err := export.ResolveConflicts(config.Domino.Conflicts)
+	for _, conflict := range conflicts {
+		log(fmt.Sprintf("time cells conflict: %s/%s: %v", export.Spec, export.Name, conflict))
+	}
+
+	if err != nil {
+		log(fmt.Sprintf("skip doctor's schedule: %v: %s/%s", err, export.Spec, export.Name))
+
+		return false
+	}
+
+	export.Reshape(config.Domino.Reshape.Rule(export.DominoSpec))
+
+	crossing := export.ApplyMidnightPolicy(config.Domino.Midnight)
+	if crossing != 0 && config.Domino.Midnight == domino.MidnightDrop {
+		log(fmt.Sprintf("time cells crossing midnight dropped: %s/%s: %d", export.Spec, export.Name, crossing))
+	}
+
+	report.OutOfHours = append(report.OutOfHours, hours.Apply(&config.WorkingHours, export)...)
+
+	return true
+}
+
+// mergeSpecialties merges schedules of doctors with several specialties, time cells of different
+// specialties overlapped are resolved by the conflicts policy; if the policy rejects the merged schedule
+// the schedules of the specialties are published separately
+func mergeSpecialties(
+	config *Config,
+	exports []*domino.DoctorSchedule,
+	report *ScheduleReport,
+	log ErrorLogger,
+) []*domino.DoctorSchedule {
+	result := make([]*domino.DoctorSchedule, 0, len(exports))
+
+	for _, export := range domino.MergeSpecialties(exports, config.Domino.PrimarySpecialties) {
+		conflicts, err := export.ResolveConflicts(config.Domino.Conflicts)
+		for _, conflict := range conflicts {
+			log(fmt.Sprintf("time cells conflict: %s/%s: %v", export.Spec, export.Name, conflict))
+		}
+
 		if err != nil {
-			log(fmt.Sprintf("failed to create a new doctors schedule: %v: %v", err, export))
+			log(fmt.Sprintf("specialties published separately: %v: %s/%s", err, export.Spec, export.Name))
+
+			result = append(result, export.Parts...)
 
-			return
+			continue
 		}
 
-		for _, cell := range export.Cells {
-			if err := doctorSchedule.AddTimeCell(cell.StartTime, cell.Duration, cell.Free, cell.Room); err != nil {
-				log(fmt.Sprintf("failed to append time cell: %v: %s/%s: %v", err, export.Spec, export.Name, cell))
+		if len(export.Specs) > 1 {
+			merged := fmt.Sprintf("%s (%s)", export.Name, strings.Join(export.Specs, ", "))
+			report.Merged = append(report.Merged, merged)
 
-				continue
+			if export.ID == "" {
+				report.MergedByName = append(report.MergedByName, merged)
 			}
 		}
 
-		if err := schedule.AddDoctorSchedule(doctorSchedule); err != nil {
-			log(fmt.Sprintf("failed to append a doctor schedule: %v", err))
+		result = append(result, export)
+	}
+
+	return result
+}
+
+func addDoctorSchedule(
+	config *Config,
+	schedule *prodoctorov.Schedule,
+	export *domino.DoctorSchedule,
+	log ErrorLogger,
+) {
+	newDoctorSchedule := prodoctorov.NewDoctorSchedule
+	if export.IsMerged() {
+		newDoctorSchedule = prodoctorov.NewMergedDoctorSchedule
+	}
 
-			return
+	doctorSchedule, err := newDoctorSchedule(export.ID, export.Name, export.Spec, len(export.Cells))
+	if err != nil {
+		log(fmt.Sprintf("failed to create a new doctors schedule: %v: %v", err, export))
+
+		return
+	}
+
+	for _, cell := range export.Cells {
+		if err := doctorSchedule.AddTimeCell(cell.StartTime, cell.Duration, cell.Free, cell.Room); err != nil {
+			log(fmt.Sprintf("failed to append time cell: %v: %s/%s: %v", err, export.Spec, export.Name, cell))
 		}
-	})
+	}
+
+	if export.Filial != "" {
+		err = schedule.AddFilialDoctorSchedule(export.Filial, doctorSchedule)
+	} else {
+		err = schedule.AddDoctorSchedule(doctorSchedule)
+	}
 
-	return schedule, err
+	if err != nil {
+		log(fmt.Sprintf("failed to append a doctor schedule: %v", err))
+	}
 }
@@ -10,6 +10,7 @@ import (
 
 	"prodoctorov/internal/service"
 	"prodoctorov/internal/service/domino"
+	"prodoctorov/internal/service/prodoctorov"
 )
 
 var (
@@ -31,13 +32,14 @@ func jsonUnmarshal(t *testing.T, j []byte) interface{} {
 
 func TestCreateSchedule(t *testing.T) {
 	type args struct {
-		filialID       string
+		config         *service.Config
 		dominoSchedule domino.Records
 		log            service.ErrorLogger
 	}
 
-	dominoSchedule, err := domino.ImportRecords(
+	dominoSchedule, _, err := domino.ImportRecords(
 		fromCSV,
+		&domino.Config{},
 		time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
 		func(message string) {
 			fmt.Println(message) //nolint:revive // has warning messages
@@ -56,7 +58,9 @@ func TestCreateSchedule(t *testing.T) {
 		{
 			name: "complex test",
 			args: args{
-				filialID:       "OOO HealthCare",
+				config: &service.Config{
+					Prodoctorov: prodoctorov.Config{FilialName: "OOO HealthCare"},
+				},
 				dominoSchedule: dominoSchedule,
 				log: func(message string) {
 					t.Error(message)
@@ -71,7 +75,7 @@ func TestCreateSchedule(t *testing.T) {
 		tt := tt
 
 		t.Run(tt.name, func(t *testing.T) {
-			schedule, err := service.CreateSchedule(tt.args.filialID, tt.args.dominoSchedule, tt.args.log)
+			schedule, _, err := service.CreateSchedule(tt.args.config, tt.args.dominoSchedule, tt.args.log)
 			if err != nil {
 				t.Errorf("CreateSchedule() error = %v, wantErr %v", err, tt.wantErr)
 
@@ -95,3 +99,59 @@ func TestCreateSchedule(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateSchedule_MergeSpecialties(t *testing.T) {
+	records := func(secondHour int) domino.Records {
+		return domino.Records{
+			{
+				Spec: "Терапевт", Name: "Иванов И.И.", Free: true,
+				StartTime: time.Date(2021, 7, 1, 10, 0, 0, 0, time.UTC), Duration: time.Hour,
+			},
+			{
+				Spec: "Гастроэнтеролог", Name: "Иванов И.И.", Free: true,
+				StartTime: time.Date(2021, 7, 1, secondHour, 30, 0, 0, time.UTC), Duration: time.Hour,
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		records     domino.Records
+		wantDoctors int
+		wantMerged  []string
+	}{
+		{
+			name:        "merged",
+			records:     records(12),
+			wantDoctors: 1,
+			wantMerged:  []string{"Иванов И.И. (Гастроэнтеролог, Терапевт)"},
+		},
+		{name: "rejected", records: records(10), wantDoctors: 2, wantMerged: nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			config := &service.Config{
+				Prodoctorov: prodoctorov.Config{FilialName: "OOO HealthCare"},
+				Domino:      domino.Config{MergeSpecialties: true, Conflicts: domino.ConflictReject},
+			}
+
+			schedule, report, err := service.CreateSchedule(config, tt.records, func(message string) {
+				t.Log(message)
+			})
+			if err != nil {
+				t.Fatalf("CreateSchedule() error = %v", err)
+			}
+
+			if got := schedule.Stats().Doctors; got != tt.wantDoctors {
+				t.Errorf("CreateSchedule() got %d doctors, want %d", got, tt.wantDoctors)
+			}
+
+			if !reflect.DeepEqual(report.Merged, tt.wantMerged) || !reflect.DeepEqual(report.MergedByName, tt.wantMerged) {
+				t.Errorf("CreateSchedule() got merged = %v, by name = %v", report.Merged, report.MergedByName)
+			}
+		})
+	}
+}
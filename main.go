@@ -7,15 +7,40 @@ import (
 	"os/signal"
 
 	"prodoctorov/internal/service"
+	"prodoctorov/internal/service/calendar"
 )
 
 func main() {
 	configFileName := flag.String("config", "cfg/config.yaml", "Name of config file in Yaml format")
+	allowMassDeletion := flag.Bool("allow-mass-deletion", false,
+		"Upload the first schedule reaching the mass-deletion guard even if the guard refuses it, next sessions are checked")
+	addClosure := flag.String("add-closure", "",
+		"Add closure of the day or the days range (2006-01-02 or 2006-01-02..2006-01-09) to the calendar and exit")
+	closureFilial := flag.String("closure-filial", "", "Filial of the closure added, all filials if empty")
+	closureSpec := flag.String("closure-spec", "", "Specialty of the closure added, all specialties if empty")
+	closureComment := flag.String("closure-comment", "", "Comment of the closure added")
 	flag.Parse()
 
+	if *addClosure != "" {
+		closure, err := calendar.NewClosure(*addClosure, *closureFilial, *closureSpec, *closureComment)
+		if err == nil {
+			err = service.AddClosure(*configFileName, closure)
+		}
+
+		if err != nil {
+			log.Printf("Failed to add closure: %v", err)
+
+			os.Exit(1)
+		}
+
+		log.Printf("Closure added: %s", closure)
+
+		return
+	}
+
 	log.Printf("Server starting with config file: %s", *configFileName)
 
-	s, err := service.NewService(*configFileName)
+	s, err := service.NewService(*configFileName, *allowMassDeletion)
 	if err != nil {
 		log.Printf("Failed to initialize service: %v", err)
 